@@ -8,6 +8,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/internal/tui"
 	"github.com/xifan2333/dmnotifier/internal/tui/business"
 
@@ -16,8 +18,18 @@ import (
 	_ "github.com/xifan2333/dmnotifier/plugins/consumers/tts"
 	_ "github.com/xifan2333/dmnotifier/plugins/consumers/tui"
 	_ "github.com/xifan2333/dmnotifier/plugins/consumers/webview"
+	_ "github.com/xifan2333/dmnotifier/plugins/filters/dedup"
+	_ "github.com/xifan2333/dmnotifier/plugins/filters/expression"
+	_ "github.com/xifan2333/dmnotifier/plugins/filters/keyword"
 	_ "github.com/xifan2333/dmnotifier/plugins/filters/message_type"
+	_ "github.com/xifan2333/dmnotifier/plugins/filters/ratelimit"
+	_ "github.com/xifan2333/dmnotifier/plugins/filters/userblock"
+	_ "github.com/xifan2333/dmnotifier/plugins/transforms/aggregate"
+	_ "github.com/xifan2333/dmnotifier/plugins/transforms/currency"
 	_ "github.com/xifan2333/dmnotifier/plugins/transforms/format"
+	_ "github.com/xifan2333/dmnotifier/plugins/transforms/speech"
+
+	execplugin "github.com/xifan2333/dmnotifier/plugins/exec"
 )
 
 var businessManager *business.Manager
@@ -46,6 +58,9 @@ func main() {
 	// 创建业务逻辑管理器
 	businessManager = business.NewManager(p, config)
 
+	// 注册外部进程插件（事件转发启动后注册，失败显示在状态栏）
+	registerExecPlugins(config.Pipeline.ExecPlugins)
+
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
@@ -55,6 +70,24 @@ func main() {
 	businessManager.Cleanup()
 }
 
+// registerExecPlugins 注册配置中定义的外部进程插件
+func registerExecPlugins(configs []tuimsg.ExecPluginConfig) {
+	for _, cfg := range configs {
+		err := execplugin.Register(execplugin.Definition{
+			Name:    cfg.Name,
+			Type:    plugin.PluginType(cfg.Type),
+			Command: cfg.Command,
+			Args:    cfg.Args,
+			Env:     cfg.Env,
+			Dir:     cfg.Dir,
+			Timeout: cfg.Timeout,
+		})
+		if err != nil {
+			event.Publish(event.PluginError("exec_plugins", err))
+		}
+	}
+}
+
 // BusinessLogicMiddleware 业务逻辑中间件
 type BusinessLogicMiddleware struct {
 	model      tea.Model
@@ -82,6 +115,18 @@ func (m *BusinessLogicMiddleware) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tuimsg.RefreshServicesRequestMsg:
 		cmds = append(cmds, businessManager.FetchServices())
 
+	case tuimsg.LoadDeadLettersRequestMsg:
+		cmds = append(cmds, businessManager.LoadDeadLetters())
+
+	case tuimsg.BlockUserRequestMsg:
+		cmds = append(cmds, businessManager.BlockUser(msg.User))
+
+	case tuimsg.UnblockUserRequestMsg:
+		cmds = append(cmds, businessManager.UnblockUser(msg.User))
+
+	case tuimsg.LoadBlockListRequestMsg:
+		cmds = append(cmds, businessManager.LoadBlockList())
+
 	case tuimsg.StopServiceRequestMsg:
 		cmds = append(cmds, businessManager.StopService(msg.Platform, msg.RID))
 
@@ -0,0 +1,176 @@
+// Package blocklist 维护用户屏蔽列表
+//
+// 用户按平台 + 用户名识别，原始数据中带有用户 ID 时同时记录 ID：
+// 双方都有 ID 时只比较 ID，用户改名后仍然命中。列表以 JSON 保存在配置目录，
+// 由 TUI 修改、user_block_filter 读取，进程内共享同一个 Default。
+package blocklist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// User 用户标识
+type User struct {
+	Platform string `json:"platform"`
+	Name     string `json:"name"`
+	UserID   string `json:"user_id,omitempty"` // 平台用户 ID，原始数据中没有时为空
+}
+
+// String 返回 "平台/用户名" 形式的描述
+func (u User) String() string {
+	return u.Platform + "/" + u.Name
+}
+
+// matches 返回 u 是否与屏蔽记录 blocked 是同一用户
+func (u User) matches(blocked User) bool {
+	if u.Platform != blocked.Platform {
+		return false
+	}
+	if u.UserID != "" && blocked.UserID != "" {
+		return u.UserID == blocked.UserID
+	}
+	return u.Name != "" && u.Name == blocked.Name
+}
+
+// Entry 屏蔽记录
+type Entry struct {
+	User
+	Added time.Time `json:"added"`
+}
+
+// Store 屏蔽列表，并发安全
+type Store struct {
+	mu      sync.RWMutex
+	path    string // 为空时只保存在内存中
+	entries []Entry
+}
+
+// Default 进程内共享的屏蔽列表
+var Default = &Store{}
+
+// Load 从文件加载屏蔽列表，之后的修改都会写回该文件
+//
+// 文件不存在时得到空列表。
+func (s *Store) Load(path string) error {
+	var entries []Entry
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return fmt.Errorf("read block list: %w", err)
+	default:
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("parse block list %s: %w", path, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.path = path
+	s.entries = entries
+	return nil
+}
+
+// Contains 返回用户是否被屏蔽
+func (s *Store) Contains(u User) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, entry := range s.entries {
+		if u.matches(entry.User) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 屏蔽用户并保存，已屏蔽时返回 false
+//
+// 已有同名记录但缺少用户 ID 时补上 ID。
+func (s *Store) Add(u User) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, entry := range s.entries {
+		if !u.matches(entry.User) {
+			continue
+		}
+		if entry.UserID != "" || u.UserID == "" {
+			return false, nil
+		}
+		s.entries[i].UserID = u.UserID
+		return false, s.save()
+	}
+
+	s.entries = append(s.entries, Entry{User: u, Added: time.Now()})
+	return true, s.save()
+}
+
+// Remove 取消屏蔽并保存，未屏蔽时返回 false
+func (s *Store) Remove(u User) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0]
+	removed := false
+	for _, entry := range s.entries {
+		if u.matches(entry.User) {
+			removed = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	s.entries = kept
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.save()
+}
+
+// List 返回屏蔽记录副本（最新的在前）
+func (s *Store) List() []Entry {
+	s.mu.RLock()
+	entries := append([]Entry(nil), s.entries...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Added.After(entries[j].Added)
+	})
+	return entries
+}
+
+// save 写回文件，调用方持有锁
+//
+// 先写临时文件再重命名，避免写到一半时退出损坏列表。
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal block list: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create block list directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write block list: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write block list: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package blocklist
+
+import (
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// FromMessage 返回消息发送者，没有发送者的消息（如直播结束）返回 false
+//
+// 用户 ID 来自消息元数据（models.Message.Sender），平台原始数据中没有 ID 时为空。
+func FromMessage(msg *models.Message) (User, bool) {
+	sender := msg.Sender()
+	if sender == nil || sender.Name == "" {
+		return User{}, false
+	}
+
+	return User{
+		Platform: string(msg.Platform),
+		Name:     sender.Name,
+		UserID:   sender.ID,
+	}, true
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// State WebSocket 连接状态
+type State string
+
+const (
+	StateConnecting   State = "connecting"   // 首次连接中
+	StateConnected    State = "connected"    // 已连接
+	StateReconnecting State = "reconnecting" // 连接断开，等待重连
+	StateFailed       State = "failed"       // 连接失败且不再重试
+	StateClosed       State = "closed"       // 已主动关闭
+)
+
+// 默认重连参数
+const (
+	DefaultReconnectDelay    = 1 * time.Second
+	DefaultMaxReconnectDelay = 30 * time.Second
+	DefaultReconnectJitter   = 0.2
+)
+
+// StateChange 连接状态变化
+type StateChange struct {
+	State   State
+	Attempt int           // 重连中为第几次尝试，其余状态为 0
+	Delay   time.Duration // 重连中为本次尝试前的等待时间
+	Err     error         // 导致断开或上一次尝试失败的错误
+	Stats   Stats         // 变化后的统计
+}
+
+// Stats 连接统计
+type Stats struct {
+	State      State
+	Since      time.Time // 进入当前状态的时间
+	Reconnects int       // 成功重连的次数
+	Failures   int       // 失败的连接尝试次数（含首次连接）
+	LastError  error     // 最近一次断开或连接失败的原因
+}
+
+// backoff 重连等待时间的指数退避
+//
+// 每次失败后等待时间翻倍，不超过上限；实际等待在 ±jitter 比例内随机浮动，
+// 避免大量客户端在服务端重启后同时重连。
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	jitter  float64
+	current time.Duration
+}
+
+// next 返回下一次等待时间并翻倍
+func (b *backoff) next() time.Duration {
+	if b.current <= 0 {
+		b.current = b.initial
+	}
+
+	delay := b.current
+	b.current *= 2
+	if b.current > b.max {
+		b.current = b.max
+	}
+
+	if b.jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.jitter * float64(delay))
+	}
+	return delay
+}
+
+// reset 连接成功后从初始等待时间重新开始
+func (b *backoff) reset() {
+	b.current = 0
+}
@@ -9,9 +9,20 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/metrics"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
 
+// eventSource 事件来源标识
+const eventSource = "websocket"
+
+// 重连结果计数
+var (
+	reconnectSuccesses = metrics.DefaultRegistry.Counter("dmnotifier_websocket_reconnects_total", "WebSocket reconnect attempts.", metrics.Labels{"result": "success"})
+	reconnectFailures  = metrics.DefaultRegistry.Counter("dmnotifier_websocket_reconnects_total", "WebSocket reconnect attempts.", metrics.Labels{"result": "failure"})
+)
+
 // MessageHandler 消息处理函数类型
 type MessageHandler func(*models.Message) error
 
@@ -31,14 +42,22 @@ type WSClient struct {
 
 	// 重连配置
 	enableReconnect   bool
-	reconnectDelay    time.Duration
+	backoff           backoff
 	maxReconnectTries int
 
+	// 连接断开时通知重连协程
+	disconnected chan error
+
 	// 状态管理
 	connected bool
 	closed    bool
 	closeMu   sync.RWMutex
 
+	// 连接状态与统计
+	onStateChange func(StateChange)
+	stats         Stats
+	statsMu       sync.Mutex
+
 	// 上下文控制
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -52,14 +71,25 @@ type WSClientConfig struct {
 	Port              int
 	Handler           MessageHandler
 	EnableReconnect   bool
-	ReconnectDelay    time.Duration
+	ReconnectDelay    time.Duration // 首次重连前的等待时间，之后每次失败翻倍
+	MaxReconnectDelay time.Duration // 重连等待时间上限
+	ReconnectJitter   float64       // 等待时间随机浮动的比例（0-1），负数表示不浮动
 	MaxReconnectTries int
+
+	// OnStateChange 连接状态变化时在客户端的协程中同步调用，不应阻塞或调用 Close
+	OnStateChange func(StateChange)
 }
 
 // NewWSClient 创建新的 WebSocket 客户端
 func NewWSClient(config WSClientConfig) *WSClient {
-	if config.ReconnectDelay == 0 {
-		config.ReconnectDelay = 5 * time.Second
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = DefaultReconnectDelay
+	}
+	if config.MaxReconnectDelay < config.ReconnectDelay {
+		config.MaxReconnectDelay = max(DefaultMaxReconnectDelay, config.ReconnectDelay)
+	}
+	if config.ReconnectJitter == 0 {
+		config.ReconnectJitter = DefaultReconnectJitter
 	}
 	if config.MaxReconnectTries == 0 {
 		config.MaxReconnectTries = -1 // -1 表示无限重试
@@ -85,15 +115,27 @@ func NewWSClient(config WSClientConfig) *WSClient {
 		url:               wsURL,
 		handler:           config.Handler,
 		enableReconnect:   config.EnableReconnect,
-		reconnectDelay:    config.ReconnectDelay,
 		maxReconnectTries: config.MaxReconnectTries,
-		ctx:               ctx,
-		cancel:            cancel,
+		backoff: backoff{
+			initial: config.ReconnectDelay,
+			max:     config.MaxReconnectDelay,
+			jitter:  min(config.ReconnectJitter, 1),
+		},
+		disconnected:  make(chan error, 1),
+		onStateChange: config.OnStateChange,
+		stats:         Stats{Since: time.Now()},
+		ctx:           ctx,
+		cancel:        cancel,
 	}
 }
 
 // Connect 连接到 WebSocket 服务器
 func (c *WSClient) Connect() error {
+	return c.connect(false)
+}
+
+// connect 建立连接并启动接收协程，reconnect 为 true 时计入重连次数
+func (c *WSClient) connect(reconnect bool) error {
 	c.closeMu.RLock()
 	if c.closed {
 		c.closeMu.RUnlock()
@@ -111,6 +153,13 @@ func (c *WSClient) Connect() error {
 	c.connected = true
 	c.connMu.Unlock()
 
+	if reconnect {
+		c.statsMu.Lock()
+		c.stats.Reconnects++
+		c.statsMu.Unlock()
+	}
+	c.setState(StateChange{State: StateConnected})
+
 	// 启动消息接收协程
 	c.wg.Add(1)
 	go c.readLoop()
@@ -119,12 +168,19 @@ func (c *WSClient) Connect() error {
 }
 
 // Start 启动客户端（带重连）
+//
+// 启用重连时首次连接失败不返回错误，而是进入重连状态在后台重试。
 func (c *WSClient) Start() error {
+	c.setState(StateChange{State: StateConnecting})
+
 	if err := c.Connect(); err != nil {
+		c.recordFailure(err)
 		if !c.enableReconnect {
+			c.setState(StateChange{State: StateFailed, Err: err})
 			return err
 		}
-
+		event.Publish(event.Reconnect(eventSource, "initial connect failed, will retry", err))
+		c.notifyDisconnected(err)
 	}
 
 	// 如果启用重连，启动重连监控
@@ -164,15 +220,18 @@ func (c *WSClient) readLoop() {
 		// 读取消息
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-
+			// 主动关闭时不上报
+			if c.ctx.Err() == nil {
+				event.Publish(event.Reconnect(eventSource, "connection lost", err))
+				c.setDisconnected()
+				c.notifyDisconnected(err)
 			}
 			return
 		}
 
 		// 解析消息
 		if err := c.handleMessage(message); err != nil {
-
+			event.Publish(event.ParseFailure(eventSource, err))
 		}
 	}
 }
@@ -194,45 +253,71 @@ func (c *WSClient) handleMessage(rawMsg []byte) error {
 	return nil
 }
 
-// reconnectLoop 重连循环
+// reconnectLoop 重连循环，连接断开时按指数退避重连
 func (c *WSClient) reconnectLoop() {
 	defer c.wg.Done()
 
-	retries := 0
-
 	for {
 		select {
 		case <-c.ctx.Done():
-
 			return
-		default:
+		case err := <-c.disconnected:
+			if !c.reconnect(err) {
+				return
+			}
 		}
+	}
+}
 
-		// 检查是否已连接
-		c.connMu.RLock()
-		connected := c.connected
-		c.connMu.RUnlock()
-
-		if connected {
-			time.Sleep(1 * time.Second)
-			continue
-		}
+// reconnect 重连直到成功，超过最大重试次数或客户端关闭时返回 false
+func (c *WSClient) reconnect(cause error) bool {
+	lastErr := cause
 
+	for attempt := 1; ; attempt++ {
 		// 检查是否超过最大重试次数
-		if c.maxReconnectTries > 0 && retries >= c.maxReconnectTries {
-
-			return
+		if c.maxReconnectTries > 0 && attempt > c.maxReconnectTries {
+			e := event.Reconnect(eventSource, fmt.Sprintf("giving up after %d attempts", attempt-1), lastErr)
+			e.Level = event.LevelError
+			e.State = string(StateFailed)
+			event.Publish(e)
+			c.setState(StateChange{State: StateFailed, Err: lastErr})
+			return false
 		}
 
-		retries++
+		delay := c.backoff.next()
+		c.setState(StateChange{State: StateReconnecting, Attempt: attempt, Delay: delay, Err: lastErr})
 
-		if err := c.Connect(); err != nil {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
 
-			time.Sleep(c.reconnectDelay)
+		if err := c.connect(true); err != nil {
+			lastErr = err
+			c.recordFailure(err)
+			reconnectFailures.Inc()
+			event.Publish(event.Reconnect(eventSource, fmt.Sprintf("reconnect attempt %d failed", attempt), err))
 			continue
 		}
 
-		retries = 0
+		c.backoff.reset()
+		reconnectSuccesses.Inc()
+		event.Publish(event.Reconnect(eventSource, fmt.Sprintf("reconnected after %d attempts", attempt), nil))
+		return true
+	}
+}
+
+// notifyDisconnected 通知重连协程连接已断开（未启用重连时忽略）
+func (c *WSClient) notifyDisconnected(err error) {
+	if !c.enableReconnect {
+		c.setState(StateChange{State: StateFailed, Err: err})
+		return
+	}
+
+	select {
+	case c.disconnected <- err:
+	default:
 	}
 }
 
@@ -275,9 +360,53 @@ func (c *WSClient) Close() error {
 	// 等待所有协程结束
 	c.wg.Wait()
 
+	c.setState(StateChange{State: StateClosed})
+
 	return nil
 }
 
+// State 返回当前连接状态
+func (c *WSClient) State() State {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.stats.State
+}
+
+// Stats 返回连接统计
+func (c *WSClient) Stats() Stats {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.stats
+}
+
+// setState 更新连接状态并通知订阅者，关闭后不再变化
+func (c *WSClient) setState(change StateChange) {
+	c.statsMu.Lock()
+	if c.stats.State == StateClosed {
+		c.statsMu.Unlock()
+		return
+	}
+	c.stats.State = change.State
+	c.stats.Since = time.Now()
+	if change.Err != nil {
+		c.stats.LastError = change.Err
+	}
+	change.Stats = c.stats
+	c.statsMu.Unlock()
+
+	if c.onStateChange != nil {
+		c.onStateChange(change)
+	}
+}
+
+// recordFailure 记录一次失败的连接尝试
+func (c *WSClient) recordFailure(err error) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.stats.Failures++
+	c.stats.LastError = err
+}
+
 // SetMessageHandler 设置消息处理器
 func (c *WSClient) SetMessageHandler(handler MessageHandler) {
 	c.handler = handler
@@ -1,15 +1,64 @@
 package common
 
-import "github.com/xifan2333/dmnotifier/pkg/api"
+import (
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/pkg/api"
+)
 
 // PluginConfig 插件配置
 type PluginConfig struct {
 	Name         string                 `yaml:"name"`
 	Enabled      bool                   `yaml:"enabled"`
 	MessageTypes []string               `yaml:"messagetypes"`
+	Input        string                 `yaml:"input,omitempty"`      // 上游共享阶段 ID，为空时直接接收原始消息
+	Filters      []StageConfig          `yaml:"filters,omitempty"`    // 过滤器链（在消息类型过滤之后按顺序执行）
+	Transforms   []StageConfig          `yaml:"transforms,omitempty"` // 转换器链（未配置且无 Input 时使用 format_transform）
+	Timeout      time.Duration          `yaml:"timeout,omitempty"`    // 单次消费超时（如：10s）
+	Retry        RetryConfig            `yaml:"retry,omitempty"`      // 可重试错误的重试策略
 	Config       map[string]interface{} `yaml:"config,omitempty"`
 }
 
+// RetryConfig 消费重试配置，零值使用默认值
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts,omitempty"` // 最多尝试次数（含首次），1 表示不重试
+	Backoff     time.Duration `yaml:"backoff,omitempty"`      // 首次重试等待时间，之后每次翻倍
+	MaxBackoff  time.Duration `yaml:"max_backoff,omitempty"`  // 重试等待上限
+}
+
+// StageConfig 过滤器/转换器阶段配置
+type StageConfig struct {
+	Name    string                 `yaml:"name"`
+	OnError string                 `yaml:"on_error,omitempty"` // 过滤器出错或熔断时：block（默认，拦截）或 pass（放行）
+	Config  map[string]interface{} `yaml:"config,omitempty"`
+}
+
+// SharedStageConfig 共享阶段配置
+//
+// 共享阶段只运行一次过滤器和转换器，结果分发给所有以其 ID 为 Input 的阶段或插件。
+type SharedStageConfig struct {
+	ID         string        `yaml:"id"`
+	Input      string        `yaml:"input,omitempty"` // 上游共享阶段 ID，为空时直接接收原始消息
+	Filters    []StageConfig `yaml:"filters,omitempty"`
+	Transforms []StageConfig `yaml:"transforms,omitempty"`
+}
+
+// ExecPluginConfig 外部进程插件定义
+//
+// 注册后即可像内置插件一样按 Name 在过滤器链、转换器链或消费者列表中引用。
+type ExecPluginConfig struct {
+	Name    string        `yaml:"name"`
+	Type    string        `yaml:"type"`              // filter, transform, consumer
+	Command string        `yaml:"command"`           // 可执行文件
+	Args    []string      `yaml:"args,omitempty"`    // 命令行参数
+	Env     []string      `yaml:"env,omitempty"`     // 追加的环境变量（KEY=VALUE）
+	Dir     string        `yaml:"dir,omitempty"`     // 工作目录
+	Timeout time.Duration `yaml:"timeout,omitempty"` // 单次请求超时（默认 5s）
+}
+
 // UI 消息类型
 type ShowServicesPopupMsg struct{}
 type ShowServerConfigPopupMsg struct{}
@@ -17,6 +66,8 @@ type ShowPluginsConfigPopupMsg struct {
 	Plugins []PluginConfig
 }
 type ShowAddServicePopupMsg struct{}
+type ShowDeadLettersPopupMsg struct{}
+type ShowBlockListPopupMsg struct{}
 type HidePopupMsg struct{}
 
 // 数据消息类型
@@ -51,6 +102,31 @@ type RefreshServicesRequestMsg struct{}
 
 type SaveConfigRequestMsg struct{}
 
+type LoadDeadLettersRequestMsg struct{}
+
+// DeadLettersLoadedMsg 死信记录加载完成（最新的在前）
+type DeadLettersLoadedMsg struct {
+	Entries []deadletter.Entry
+	Err     error
+}
+
+// BlockUserRequestMsg 屏蔽用户
+type BlockUserRequestMsg struct {
+	User blocklist.User
+}
+
+// UnblockUserRequestMsg 取消屏蔽用户
+type UnblockUserRequestMsg struct {
+	User blocklist.User
+}
+
+type LoadBlockListRequestMsg struct{}
+
+// BlockListLoadedMsg 屏蔽列表加载完成（最新的在前）
+type BlockListLoadedMsg struct {
+	Entries []blocklist.Entry
+}
+
 // 配置更新消息
 type UpdateServerConfigMsg struct {
 	APIAddress string
@@ -80,3 +156,36 @@ type ErrorMsg struct {
 type SuccessMsg struct {
 	Message string
 }
+
+// PluginStateMsg 插件熔断状态变化（Source 为 "管道/插件"）
+type PluginStateMsg struct {
+	Source string
+	State  string
+}
+
+// PluginHealthMsg 消费者插件实例生命周期状态变化（ID 为 "管道/插件"）
+type PluginHealthMsg struct {
+	ID    string
+	Name  string
+	State string
+	Err   string // 降级或失败原因
+}
+
+// EventMsg 事件总线转发到 TUI 的事件
+type EventMsg struct {
+	Event event.Event
+}
+
+// RoomStatsMsg 直播间统计更新，显示在标题栏
+type RoomStatsMsg struct {
+	Online int64
+}
+
+// ConnectionStateMsg WebSocket 连接状态变化，显示在标题栏
+type ConnectionStateMsg struct {
+	State      string        // connecting、connected、reconnecting、failed 或 closed
+	Attempt    int           // 重连中为第几次尝试
+	Delay      time.Duration // 重连中为本次尝试前的等待时间
+	Reconnects int           // 成功重连的次数
+	Err        error
+}
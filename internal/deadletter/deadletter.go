@@ -0,0 +1,138 @@
+package deadletter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// Entry 死信记录：重试耗尽仍消费失败的消息
+type Entry struct {
+	Time     time.Time       `json:"time"`
+	Pipeline string          `json:"pipeline"`
+	Plugin   string          `json:"plugin"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Content  string          `json:"content,omitempty"` // 格式化后的内容（如有）
+	Message  *models.Message `json:"message"`
+}
+
+// UnmarshalJSON 解析死信记录，消息本身无法解析时保留其余字段
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type alias Entry
+	aux := struct {
+		*alias
+		Message json.RawMessage `json:"message"`
+	}{
+		alias: (*alias)(e),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	e.Message = nil
+	var msg models.Message
+	if err := json.Unmarshal(aux.Message, &msg); err == nil {
+		e.Message = &msg
+	}
+
+	return nil
+}
+
+// Sink 死信接收器
+type Sink interface {
+	Write(entry Entry) error
+}
+
+// FileSink 以 JSON Lines 格式追加写入文件的死信接收器
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink 创建文件死信接收器
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Path 返回文件路径
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+// Write 追加一条死信记录
+func (s *FileSink) Write(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal dead letter: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create dead letter directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open dead letter file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// ReadRecent 读取最近 n 条死信记录（最新的在前），文件不存在时返回空列表
+//
+// 无法解析的行会被跳过。
+func ReadRecent(path string, n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open dead letter file: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0, n)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		// 只保留最后 n 条
+		if len(entries) == n {
+			entries = append(entries[:0], entries[1:]...)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dead letter file: %w", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
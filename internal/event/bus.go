@@ -0,0 +1,90 @@
+package event
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 默认订阅缓冲长度
+const defaultSubscriberBuffer = 64
+
+// Bus 事件总线
+//
+// 发布不会阻塞：订阅者缓冲已满时该订阅者会丢失此事件。
+type Bus struct {
+	subscribers map[int]chan Event
+	nextID      int
+	mu          sync.RWMutex
+	dropped     atomic.Uint64
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]chan Event),
+	}
+}
+
+// Publish 发布事件
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe 订阅事件，返回事件通道和取消订阅函数
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = defaultSubscriberBuffer
+	}
+
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Dropped 返回因订阅者缓冲已满而丢失的事件数
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// DefaultBus 全局事件总线
+var DefaultBus = NewBus()
+
+// Publish 发布事件到全局事件总线
+func Publish(e Event) {
+	DefaultBus.Publish(e)
+}
+
+// Subscribe 订阅全局事件总线
+func Subscribe(buffer int) (<-chan Event, func()) {
+	return DefaultBus.Subscribe(buffer)
+}
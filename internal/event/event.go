@@ -0,0 +1,190 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Type 事件类型
+type Type string
+
+const (
+	TypePluginError    Type = "plugin_error"    // 插件执行失败
+	TypeMessageDropped Type = "message_dropped" // 消息因队列溢出被丢弃
+	TypeParseFailure   Type = "parse_failure"   // 消息解析失败
+	TypeReconnect      Type = "reconnect"       // WebSocket 重连
+	TypePluginStatus   Type = "plugin_status"   // 插件状态提示
+	TypePluginState    Type = "plugin_state"    // 插件熔断状态变化
+	TypePluginPanic    Type = "plugin_panic"    // 插件调用 panic 的调用栈
+	TypePluginHealth   Type = "plugin_health"   // 插件实例生命周期状态变化
+)
+
+// Level 事件级别
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String 返回级别名称
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLevel 解析级别名称（不区分大小写），无法识别时返回 LevelInfo
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return LevelDebug
+	case "WARN", "WARNING":
+		return LevelWarn
+	case "ERROR":
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// Event 事件
+type Event struct {
+	Type    Type      // 事件类型
+	Level   Level     // 事件级别
+	Source  string    // 事件来源（管道、插件或客户端名称）
+	Message string    // 描述信息
+	Err     error     // 关联的错误
+	State   string    // 状态变化事件的新状态
+	Time    time.Time // 发生时间
+}
+
+// String 返回事件的单行描述
+func (e Event) String() string {
+	var b strings.Builder
+	if e.Source != "" {
+		b.WriteString(e.Source)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	if e.Err != nil {
+		if e.Message != "" {
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// PluginError 创建插件错误事件
+func PluginError(source string, err error) Event {
+	return Event{
+		Type:    TypePluginError,
+		Level:   LevelError,
+		Source:  source,
+		Message: "plugin error",
+		Err:     err,
+	}
+}
+
+// MessageDropped 创建消息丢弃事件
+func MessageDropped(source, reason string) Event {
+	return Event{
+		Type:    TypeMessageDropped,
+		Level:   LevelWarn,
+		Source:  source,
+		Message: reason,
+	}
+}
+
+// ParseFailure 创建解析失败事件
+func ParseFailure(source string, err error) Event {
+	return Event{
+		Type:    TypeParseFailure,
+		Level:   LevelWarn,
+		Source:  source,
+		Message: "parse failed",
+		Err:     err,
+	}
+}
+
+// Reconnect 创建重连事件
+func Reconnect(source, message string, err error) Event {
+	level := LevelInfo
+	if err != nil {
+		level = LevelWarn
+	}
+	return Event{
+		Type:    TypeReconnect,
+		Level:   level,
+		Source:  source,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// PluginStatus 创建插件状态提示事件
+func PluginStatus(source, message string) Event {
+	return Event{
+		Type:    TypePluginStatus,
+		Level:   LevelInfo,
+		Source:  source,
+		Message: message,
+	}
+}
+
+// PluginState 创建插件熔断状态变化事件，state 为 "closed" 之外的状态按警告级别发布
+func PluginState(source, state, message string) Event {
+	level := LevelWarn
+	if state == "closed" {
+		level = LevelInfo
+	}
+	return Event{
+		Type:    TypePluginState,
+		Level:   level,
+		Source:  source,
+		Message: message,
+		State:   state,
+	}
+}
+
+// PluginPanic 创建插件 panic 调用栈事件（调试级别，仅写入日志）
+func PluginPanic(source string, stack []byte) Event {
+	return Event{
+		Type:    TypePluginPanic,
+		Level:   LevelDebug,
+		Source:  source,
+		Message: "panic stack:\n" + string(stack),
+	}
+}
+
+// PluginHealth 创建插件实例生命周期状态变化事件
+//
+// 带错误的变化（降级、启动失败等）按警告级别发布，运行和停止为信息级别，其余为调试级别。
+func PluginHealth(source, state string, err error) Event {
+	level := LevelDebug
+	switch {
+	case err != nil:
+		level = LevelWarn
+	case state == "running" || state == "stopped":
+		level = LevelInfo
+	}
+	return Event{
+		Type:    TypePluginHealth,
+		Level:   level,
+		Source:  source,
+		Message: state,
+		Err:     err,
+		State:   state,
+	}
+}
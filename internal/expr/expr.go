@@ -0,0 +1,258 @@
+// Package expr 针对消息字段的小型布尔表达式语言
+//
+// 语法（优先级从低到高）：
+//
+//	a || b
+//	a && b
+//	==  !=  <  <=  >  >=  ~（正则匹配）  !~（正则不匹配）
+//	+  -
+//	*  /  %
+//	!a  -a
+//	字面量（数字、"字符串"、true、false）、字段名、( ... )
+//
+// 编译时检查字段是否存在和类型是否匹配：算术与大小比较只用于数字，
+// ~ 的右侧必须是字符串字面量（编译为正则表达式），整个表达式必须是布尔值。
+package expr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Kind 值类型
+type Kind int
+
+const (
+	KindBool Kind = iota
+	KindNumber
+	KindString
+)
+
+// String 返回类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// Env 求值时提供字段值：字符串字段返回 string，数值字段返回 float64，
+// 布尔字段返回 bool，其他返回值按零值处理
+type Env func(field string) interface{}
+
+// SyntaxError 编译错误
+type SyntaxError struct {
+	Pos int    // 出错位置（从 1 开始的列号）
+	Msg string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+// Program 编译后的表达式
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile 编译表达式，fields 声明可用字段及其类型
+func Compile(source string, fields map[string]Kind) (*Program, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, fields: fields}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+	if root.kind() != KindBool {
+		return nil, &SyntaxError{Pos: 1, Msg: fmt.Sprintf("expression is a %s, want bool", root.kind())}
+	}
+
+	return &Program{source: source, root: root}, nil
+}
+
+// String 返回表达式源码
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval 对给定字段求值
+func (p *Program) Eval(env Env) bool {
+	return p.root.eval(env).b
+}
+
+// value 求值结果
+type value struct {
+	b   bool
+	num float64
+	str string
+}
+
+// node 语法树节点
+type node interface {
+	kind() Kind
+	eval(env Env) value
+}
+
+// literal 字面量
+type literal struct {
+	k Kind
+	v value
+}
+
+func (n *literal) kind() Kind         { return n.k }
+func (n *literal) eval(env Env) value { return n.v }
+
+// field 字段引用
+type field struct {
+	name string
+	k    Kind
+}
+
+func (n *field) kind() Kind { return n.k }
+
+func (n *field) eval(env Env) value {
+	switch v := env(n.name).(type) {
+	case bool:
+		return value{b: v}
+	case float64:
+		return value{num: v}
+	case string:
+		return value{str: v}
+	}
+	return value{}
+}
+
+// unary 一元运算
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n *unary) kind() Kind {
+	if n.op == "!" {
+		return KindBool
+	}
+	return KindNumber
+}
+
+func (n *unary) eval(env Env) value {
+	v := n.operand.eval(env)
+	if n.op == "!" {
+		return value{b: !v.b}
+	}
+	return value{num: -v.num}
+}
+
+// logical 短路逻辑运算
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n *logical) kind() Kind { return KindBool }
+
+func (n *logical) eval(env Env) value {
+	left := n.left.eval(env).b
+	if n.op == "||" {
+		return value{b: left || n.right.eval(env).b}
+	}
+	return value{b: left && n.right.eval(env).b}
+}
+
+// arithmetic 算术运算
+type arithmetic struct {
+	op          string
+	left, right node
+}
+
+func (n *arithmetic) kind() Kind { return KindNumber }
+
+func (n *arithmetic) eval(env Env) value {
+	l, r := n.left.eval(env).num, n.right.eval(env).num
+	switch n.op {
+	case "+":
+		return value{num: l + r}
+	case "-":
+		return value{num: l - r}
+	case "*":
+		return value{num: l * r}
+	case "/":
+		if r == 0 {
+			return value{}
+		}
+		return value{num: l / r}
+	case "%":
+		if int64(r) == 0 {
+			return value{}
+		}
+		return value{num: float64(int64(l) % int64(r))}
+	}
+	return value{}
+}
+
+// comparison 比较运算
+type comparison struct {
+	op          string
+	operandKind Kind
+	left, right node
+}
+
+func (n *comparison) kind() Kind { return KindBool }
+
+func (n *comparison) eval(env Env) value {
+	l, r := n.left.eval(env), n.right.eval(env)
+
+	var equal bool
+	switch n.operandKind {
+	case KindBool:
+		equal = l.b == r.b
+	case KindNumber:
+		equal = l.num == r.num
+	case KindString:
+		equal = l.str == r.str
+	}
+
+	switch n.op {
+	case "==":
+		return value{b: equal}
+	case "!=":
+		return value{b: !equal}
+	case "<":
+		return value{b: l.num < r.num}
+	case "<=":
+		return value{b: l.num <= r.num}
+	case ">":
+		return value{b: l.num > r.num}
+	case ">=":
+		return value{b: l.num >= r.num}
+	}
+	return value{}
+}
+
+// match 正则匹配
+type match struct {
+	negate bool
+	left   node
+	re     *regexp.Regexp
+}
+
+func (n *match) kind() Kind { return KindBool }
+
+func (n *match) eval(env Env) value {
+	matched := n.re.MatchString(n.left.eval(env).str)
+	return value{b: matched != n.negate}
+}
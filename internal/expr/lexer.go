@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string  // 标识符、运算符或字符串字面量的值
+	num  float64 // 数字字面量的值
+	pos  int     // 起始列（从 1 开始，按字符计）
+}
+
+// String 返回用于错误信息的描述
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators 按长度优先匹配的运算符
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "!~", "<", ">", "~", "!", "+", "-", "*", "/", "%"}
+
+// lex 把表达式切分为词法单元
+func lex(src string) ([]token, error) {
+	runes := []rune(src)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			i++
+
+		case r == '"' || r == '\'':
+			text, n, err := lexString(runes[i:])
+			if err != nil {
+				return nil, &SyntaxError{Pos: pos, Msg: err.Error()}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			i += n
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, num: num, pos: pos})
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: pos})
+
+		default:
+			op := matchOperator(runes[i:])
+			if op == "" {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: pos})
+			i += len([]rune(op))
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+// matchOperator 返回 runes 开头的运算符，没有时返回空字符串
+func matchOperator(runes []rune) string {
+	rest := string(runes[:min(len(runes), 2)])
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// lexString 解析引号包围的字符串字面量，返回值和消耗的字符数
+//
+// 支持 \\、\"、\'、\n 和 \t 转义，其余反斜杠序列原样保留。
+func lexString(runes []rune) (string, int, error) {
+	quote := runes[0]
+	var b strings.Builder
+
+	for i := 1; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case quote:
+			return b.String(), i + 1, nil
+
+		case '\\':
+			i++
+			if i >= len(runes) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch esc := runes[i]; esc {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '\\', '"', '\'':
+				b.WriteRune(esc)
+			default:
+				// 其余转义原样保留，便于书写正则（如 "\d+"）
+				b.WriteRune('\\')
+				b.WriteRune(esc)
+			}
+
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
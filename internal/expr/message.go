@@ -0,0 +1,118 @@
+package expr
+
+import (
+	"strings"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// MessageFields 消息表达式可用的字段
+var MessageFields = map[string]Kind{
+	"type":        KindString, // 消息类型（Chat、Gift ...）
+	"platform":    KindString, // 平台
+	"rid":         KindString, // 房间号
+	"user":        KindString, // 用户名
+	"uid":         KindString, // 用户 ID（原始数据中没有时为空）
+	"level":       KindNumber, // 用户等级
+	"guard":       KindNumber, // 会员等级（B 站大航海 1 总督 2 提督 3 舰长）
+	"medal":       KindString, // 粉丝勋章名称
+	"medal_level": KindNumber, // 粉丝勋章等级
+	"content":     KindString, // 聊天或 SuperChat 内容
+	"price":       KindNumber, // 礼物、订阅、会员单价或 SuperChat 金额
+	"num":         KindNumber, // 礼物、订阅数量或会员月数
+	"count":       KindNumber, // 点赞次数
+	"online":      KindNumber, // 在线人数（RoomStats）
+	"value":       KindNumber, // 总价值（经 currency_transform 归一化后可跨平台比较）
+	"currency":    KindString, // 总价值的币种
+}
+
+// CompileMessage 编译针对消息字段（MessageFields）的表达式，空表达式返回 nil
+func CompileMessage(source string) (*Program, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, nil
+	}
+	return Compile(source, MessageFields)
+}
+
+// MessageEnv 返回消息字段的取值函数，用于对 CompileMessage 得到的表达式求值
+//
+// 经过 format_transform 的消息从原始数据重新解析，以取得金额、数量等字段。
+func MessageEnv(msg *models.Message) Env {
+	var user, content string
+	var price, num, count, online float64
+	data := msg.SourceData()
+	switch d := data.(type) {
+	case *models.ChatData:
+		user, content = d.Name, d.Content
+	case *models.GiftData:
+		user, price, num = d.Name, d.Price, float64(d.Num)
+	case *models.LikeData:
+		user, count = d.Name, float64(d.Count)
+	case *models.EnterRoomData:
+		user = d.Name
+	case *models.SubscribeData:
+		user, price, num = d.Name, d.Price, float64(d.Num)
+	case *models.SuperChatData:
+		user, content, price = d.Name, d.Content, d.Price
+	case *models.GuardData:
+		user, price, num = d.Name, d.Price, float64(d.Num)
+	case *models.ShareData:
+		user = d.Name
+	case *models.FollowData:
+		user = d.Name
+	case *models.RoomStatsData:
+		online = float64(d.Online)
+	case *models.UnknownData:
+		user = d.Name
+	case *models.FormattedMessage:
+		user, content = d.UserName, d.Content
+	}
+	value, _ := models.ValueOf(data)
+
+	var sender models.User
+	if u := msg.Sender(); u != nil {
+		sender = *u
+	}
+	var medal models.Medal
+	if sender.Medal != nil {
+		medal = *sender.Medal
+	}
+
+	return func(field string) interface{} {
+		switch field {
+		case "type":
+			return string(msg.Type)
+		case "platform":
+			return string(msg.Platform)
+		case "rid":
+			return msg.RID
+		case "user":
+			return user
+		case "uid":
+			return sender.ID
+		case "level":
+			return float64(sender.Level)
+		case "guard":
+			return float64(sender.GuardLevel)
+		case "medal":
+			return medal.Name
+		case "medal_level":
+			return float64(medal.Level)
+		case "content":
+			return content
+		case "price":
+			return price
+		case "num":
+			return num
+		case "count":
+			return count
+		case "online":
+			return online
+		case "value":
+			return value.Amount
+		case "currency":
+			return value.Currency
+		}
+		return nil
+	}
+}
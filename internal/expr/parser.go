@@ -0,0 +1,266 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// parser 递归下降解析器，解析同时完成类型检查
+type parser struct {
+	tokens []token
+	pos    int
+	fields map[string]Kind
+}
+
+// peek 返回当前词法单元
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next 返回并消耗当前词法单元
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// acceptOp 当前是 ops 之一的运算符时消耗并返回它
+func (p *parser) acceptOp(ops ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOp {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return tok, true
+		}
+	}
+	return tok, false
+}
+
+// expect 检查操作数类型
+func expect(tok token, n node, want Kind) error {
+	if n.kind() != want {
+		return &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("operator %s needs %s operands, got %s", tok.text, want, n.kind())}
+	}
+	return nil
+}
+
+// parseOr or := and ("||" and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.acceptOp("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(tok, left, KindBool); err != nil {
+			return nil, err
+		}
+		if err := expect(tok, right, KindBool); err != nil {
+			return nil, err
+		}
+		left = &logical{op: tok.text, left: left, right: right}
+	}
+}
+
+// parseAnd and := comparison ("&&" comparison)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.acceptOp("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(tok, left, KindBool); err != nil {
+			return nil, err
+		}
+		if err := expect(tok, right, KindBool); err != nil {
+			return nil, err
+		}
+		left = &logical{op: tok.text, left: left, right: right}
+	}
+}
+
+// parseComparison comparison := sum (比较运算符 sum)?
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	tok, ok := p.acceptOp("==", "!=", "<", "<=", ">", ">=", "~", "!~")
+	if !ok {
+		return left, nil
+	}
+
+	// 正则匹配：右侧必须是字符串字面量
+	if tok.text == "~" || tok.text == "!~" {
+		if err := expect(tok, left, KindString); err != nil {
+			return nil, err
+		}
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, &SyntaxError{Pos: pattern.pos, Msg: fmt.Sprintf("operator %s needs a string pattern, got %s", tok.text, pattern)}
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, &SyntaxError{Pos: pattern.pos, Msg: fmt.Sprintf("invalid pattern: %v", err)}
+		}
+		return &match{negate: tok.text == "!~", left: left, re: re}, nil
+	}
+
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.text {
+	case "==", "!=":
+		if left.kind() != right.kind() {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("cannot compare %s with %s", left.kind(), right.kind())}
+		}
+	default:
+		if err := expect(tok, left, KindNumber); err != nil {
+			return nil, err
+		}
+		if err := expect(tok, right, KindNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	return &comparison{op: tok.text, operandKind: left.kind(), left: left, right: right}, nil
+}
+
+// parseSum sum := product (("+" | "-") product)*
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.acceptOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(tok, left, KindNumber); err != nil {
+			return nil, err
+		}
+		if err := expect(tok, right, KindNumber); err != nil {
+			return nil, err
+		}
+		left = &arithmetic{op: tok.text, left: left, right: right}
+	}
+}
+
+// parseProduct product := unary (("*" | "/" | "%") unary)*
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.acceptOp("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(tok, left, KindNumber); err != nil {
+			return nil, err
+		}
+		if err := expect(tok, right, KindNumber); err != nil {
+			return nil, err
+		}
+		left = &arithmetic{op: tok.text, left: left, right: right}
+	}
+}
+
+// parseUnary unary := ("!" | "-") unary | primary
+func (p *parser) parseUnary() (node, error) {
+	tok, ok := p.acceptOp("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	want := KindNumber
+	if tok.text == "!" {
+		want = KindBool
+	}
+	if err := expect(tok, operand, want); err != nil {
+		return nil, err
+	}
+
+	return &unary{op: tok.text, operand: operand}, nil
+}
+
+// parsePrimary primary := 数字 | 字符串 | true | false | 字段 | "(" or ")"
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return &literal{k: KindNumber, v: value{num: tok.num}}, nil
+
+	case tokenString:
+		return &literal{k: KindString, v: value{str: tok.text}}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literal{k: KindBool, v: value{b: true}}, nil
+		case "false":
+			return &literal{k: KindBool, v: value{b: false}}, nil
+		}
+
+		k, exists := p.fields[tok.text]
+		if !exists {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown field %s", tok.text)}
+		}
+		return &field{name: tok.text, k: k}, nil
+
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: fmt.Sprintf("expected ), got %s", closing)}
+		}
+		return inner, nil
+	}
+
+	return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+}
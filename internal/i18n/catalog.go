@@ -0,0 +1,81 @@
+package i18n
+
+// catalogs 各语言环境的消息目录
+var catalogs = map[Locale]map[string]string{
+	ZhCN: {
+		"money.CNY": "%s 元",
+		"money.USD": "%s 美元",
+
+		"format.chat":      `{{.Content}}`,
+		"format.superchat": `{{money .Total}}: {{.Content}}`,
+		"format.gift":      `送出了 {{.Num}} 个 {{.Item}} ({{money .Total}})`,
+		"format.subscribe": `订阅了 {{.Item}}`,
+		"format.like":      `点赞了 {{.Count}} 次`,
+		"format.enterroom": `进入了直播间`,
+		"format.endlive":   `直播结束`,
+		"format.guard":     `开通了 {{.Num}} 个月{{guard .Level}}`,
+		"format.share":     `分享了直播间`,
+		"format.follow":    `关注了主播`,
+		"format.roomstats": `在线人数 {{number .Online 0}}`,
+		"format.unknown":   `{{.Type}} 消息`,
+
+		"guard.1": "总督",
+		"guard.2": "提督",
+		"guard.3": "舰长",
+		"guard.0": "会员",
+
+		"tts.chat":  "%s说：%s",
+		"tts.event": "%s%s",
+	},
+	ZhTW: {
+		"money.CNY": "%s 元",
+		"money.TWD": "新臺幣 %s 元",
+		"money.USD": "%s 美元",
+
+		"format.chat":      `{{.Content}}`,
+		"format.superchat": `{{money .Total}}: {{.Content}}`,
+		"format.gift":      `送出了 {{.Num}} 個 {{.Item}} ({{money .Total}})`,
+		"format.subscribe": `訂閱了 {{.Item}}`,
+		"format.like":      `按讚了 {{.Count}} 次`,
+		"format.enterroom": `進入了直播間`,
+		"format.endlive":   `直播結束`,
+		"format.guard":     `開通了 {{.Num}} 個月{{guard .Level}}`,
+		"format.share":     `分享了直播間`,
+		"format.follow":    `關注了主播`,
+		"format.roomstats": `線上人數 {{number .Online 0}}`,
+		"format.unknown":   `{{.Type}} 訊息`,
+
+		"guard.1": "總督",
+		"guard.2": "提督",
+		"guard.3": "艦長",
+		"guard.0": "會員",
+
+		"tts.chat":  "%s說：%s",
+		"tts.event": "%s%s",
+	},
+	EnUS: {
+		"money.CNY": "CN¥%s",
+		"money.USD": "$%s",
+
+		"format.chat":      `{{.Content}}`,
+		"format.superchat": `{{money .Total}}: {{.Content}}`,
+		"format.gift":      `sent {{number .Num 0}} × {{.Item}} ({{money .Total}})`,
+		"format.subscribe": `subscribed to {{.Item}}`,
+		"format.like":      `liked {{.Count}} {{plural .Count "time" "times"}}`,
+		"format.enterroom": `joined the room`,
+		"format.endlive":   `Stream ended`,
+		"format.guard":     `bought {{.Num}} {{plural .Num "month" "months"}} of {{guard .Level}}`,
+		"format.share":     `shared the stream`,
+		"format.follow":    `followed the streamer`,
+		"format.roomstats": `{{number .Online 0}} online`,
+		"format.unknown":   `{{.Type}} message`,
+
+		"guard.1": "Governor",
+		"guard.2": "Admiral",
+		"guard.3": "Captain",
+		"guard.0": "membership",
+
+		"tts.chat":  "%s says: %s",
+		"tts.event": "%s %s",
+	},
+}
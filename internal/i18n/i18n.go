@@ -0,0 +1,167 @@
+// Package i18n 提供格式化输出的语言环境：消息目录、数字和金额格式
+//
+// 目录中 format.* 条目是 format_transform 的默认内容模板（text/template），
+// tts.* 条目是 TTS 播报句式（fmt 格式串）。缺少的条目回退到 Default。
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// Locale 语言环境
+type Locale string
+
+const (
+	ZhCN Locale = "zh-CN" // 简体中文
+	ZhTW Locale = "zh-TW" // 繁体中文
+	EnUS Locale = "en-US" // 英语（美国）
+)
+
+// Default 默认语言环境
+const Default = ZhCN
+
+// Locales 支持的语言环境
+var Locales = []Locale{ZhCN, ZhTW, EnUS}
+
+// Names 返回支持的语言环境名称，用于配置选项
+func Names() []string {
+	names := make([]string, len(Locales))
+	for i, l := range Locales {
+		names[i] = string(l)
+	}
+	return names
+}
+
+// Parse 解析语言环境，不区分大小写，接受 zh_CN 形式；空字符串返回 Default
+func Parse(s string) (Locale, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "_", "-"))
+	if s == "" {
+		return Default, nil
+	}
+	for _, l := range Locales {
+		if strings.EqualFold(s, string(l)) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported locale %q (want %s)", s, strings.Join(Names(), ", "))
+}
+
+// Validate 配置保存前检查语言环境
+func Validate(value interface{}) error {
+	s, _ := value.(string)
+	_, err := Parse(s)
+	return err
+}
+
+// Text 返回目录条目，当前语言环境缺少时回退到 Default
+func (l Locale) Text(key string) string {
+	if text, ok := catalogs[l][key]; ok {
+		return text
+	}
+	return catalogs[Default][key]
+}
+
+// Sprintf 按目录中的格式串格式化
+func (l Locale) Sprintf(key string, args ...interface{}) string {
+	return fmt.Sprintf(l.Text(key), args...)
+}
+
+// Number 格式化数字：千位分组，保留 decimals 位小数
+func (l Locale) Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteByte('.')
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Money 格式化金额（保留两位小数），目录中没有该币种时显示币种代码
+func (l Locale) Money(m models.Money) string {
+	amount := l.Number(m.Amount, 2)
+	if format := l.Text("money." + m.Currency); format != "" {
+		return fmt.Sprintf(format, amount)
+	}
+	return amount + " " + m.Currency
+}
+
+// Plural 按数量选择单复数形式，中文忽略单复数
+func (l Locale) Plural(n interface{}, one, other string) string {
+	if l != EnUS {
+		return other
+	}
+	if f, ok := toFloat(n); ok && f == 1 {
+		return one
+	}
+	return other
+}
+
+// Guard 返回会员等级名称（B 站大航海 1 总督 2 提督 3 舰长），其他等级返回通用名称
+func (l Locale) Guard(level int) string {
+	if name := l.Text("guard." + strconv.Itoa(level)); name != "" {
+		return name
+	}
+	return l.Text("guard.0")
+}
+
+// Funcs 返回模板函数：number、money（接受 models.Money 或数字）、plural、guard
+func (l Locale) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"number": func(v interface{}, decimals int) string {
+			f, _ := toFloat(v)
+			return l.Number(f, decimals)
+		},
+		"money": func(v interface{}) string {
+			return l.Money(toMoney(v))
+		},
+		"plural": l.Plural,
+		"guard":  l.Guard,
+	}
+}
+
+// toMoney 把模板中的金额参数转换为 models.Money，纯数字视为人民币
+func toMoney(v interface{}) models.Money {
+	switch m := v.(type) {
+	case models.Money:
+		return m
+	case *models.Money:
+		if m != nil {
+			return *m
+		}
+	}
+	f, _ := toFloat(v)
+	return models.Money{Amount: f, Currency: models.CurrencyCNY}
+}
+
+// toFloat 把模板中的数字参数转换为 float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
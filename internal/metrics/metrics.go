@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Labels 指标标签
+type Labels map[string]string
+
+// key 返回标签的稳定序列化结果，用于区分同一指标族中的不同序列
+func (l Labels) key() string {
+	names := l.names()
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(l[name])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// names 返回排序后的标签名
+func (l Labels) names() []string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Counter 单调递增计数器
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc 计数加一
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add 计数增加 n
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value 返回当前计数
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// DefaultLatencyBuckets 默认耗时分桶（秒）
+var DefaultLatencyBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram 直方图
+type Histogram struct {
+	buckets []float64 // 各桶上界（升序）
+	counts  []uint64  // 各桶计数（非累计）
+	count   uint64
+	sum     float64
+	mu      sync.Mutex
+}
+
+// newHistogram 创建直方图
+func newHistogram(buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	return &Histogram{
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)),
+	}
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration 记录耗时（秒）
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// Since 记录从 start 到现在的耗时
+func (h *Histogram) Since(start time.Time) {
+	h.ObserveDuration(time.Since(start))
+}
+
+// HistogramSnapshot 直方图快照
+type HistogramSnapshot struct {
+	Buckets    []float64 // 各桶上界
+	Cumulative []uint64  // 各桶累计计数
+	Count      uint64
+	Sum        float64
+}
+
+// Snapshot 返回直方图快照
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+	var total uint64
+	for i, c := range h.counts {
+		total += c
+		cumulative[i] = total
+	}
+
+	return HistogramSnapshot{
+		Buckets:    append([]float64{}, h.buckets...),
+		Cumulative: cumulative,
+		Count:      h.count,
+		Sum:        h.sum,
+	}
+}
+
+// Mean 返回平均值，没有观测值时返回 0
+func (s HistogramSnapshot) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Quantile 按分桶估算分位数（线性插值），没有观测值时返回 0
+func (s HistogramSnapshot) Quantile(q float64) float64 {
+	if s.Count == 0 {
+		return 0
+	}
+
+	rank := q * float64(s.Count)
+	var lower float64
+	var prev uint64
+	for i, upper := range s.Buckets {
+		c := s.Cumulative[i]
+		if float64(c) >= rank {
+			if c == prev {
+				return upper
+			}
+			return lower + (upper-lower)*(rank-float64(prev))/float64(c-prev)
+		}
+		lower = upper
+		prev = c
+	}
+
+	// 落在 +Inf 桶中，返回最大上界
+	if len(s.Buckets) > 0 {
+		return s.Buckets[len(s.Buckets)-1]
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,217 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// kind 指标类型
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindHistogram kind = "histogram"
+)
+
+// family 指标族（同名、同类型、不同标签的序列集合）
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+// series 单个时间序列
+type series struct {
+	labels    Labels
+	counter   *Counter
+	histogram *Histogram
+}
+
+// Registry 指标注册中心
+//
+// 同名同标签的指标只会创建一次，重复获取返回同一实例。
+type Registry struct {
+	families map[string]*family
+	mu       sync.RWMutex
+}
+
+// NewRegistry 创建指标注册中心
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// Counter 获取或创建计数器
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	s := r.getOrCreate(name, help, kindCounter, nil, labels)
+	return s.counter
+}
+
+// Histogram 获取或创建直方图，buckets 为空时使用 DefaultLatencyBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labels Labels) *Histogram {
+	s := r.getOrCreate(name, help, kindHistogram, buckets, labels)
+	return s.histogram
+}
+
+// getOrCreate 获取或创建序列
+func (r *Registry) getOrCreate(name, help string, k kind, buckets []float64, labels Labels) *series {
+	key := labels.key()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, exists := r.families[name]
+	if !exists {
+		f = &family{
+			name:    name,
+			help:    help,
+			kind:    k,
+			buckets: buckets,
+			series:  make(map[string]*series),
+		}
+		r.families[name] = f
+	}
+
+	if f.kind != k {
+		panic(fmt.Sprintf("metric %s registered as %s, requested as %s", name, f.kind, k))
+	}
+
+	s, exists := f.series[key]
+	if !exists {
+		copied := make(Labels, len(labels))
+		for k, v := range labels {
+			copied[k] = v
+		}
+		s = &series{labels: copied}
+		switch f.kind {
+		case kindCounter:
+			s.counter = &Counter{}
+		case kindHistogram:
+			s.histogram = newHistogram(f.buckets)
+		}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// WriteText 以 Prometheus 文本格式输出所有指标
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type familySnapshot struct {
+		f      *family
+		keys   []string
+		series []*series
+	}
+	snapshots := make([]familySnapshot, 0, len(names))
+	for _, name := range names {
+		f := r.families[name]
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		ss := make([]*series, len(keys))
+		for i, key := range keys {
+			ss[i] = f.series[key]
+		}
+		snapshots = append(snapshots, familySnapshot{f: f, keys: keys, series: ss})
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, snap := range snapshots {
+		f := snap.f
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+
+		for _, s := range snap.series {
+			switch f.kind {
+			case kindCounter:
+				fmt.Fprintf(bw, "%s%s %d\n", f.name, formatLabels(s.labels, "", ""), s.counter.Value())
+
+			case kindHistogram:
+				h := s.histogram.Snapshot()
+				for i, upper := range h.Buckets {
+					fmt.Fprintf(bw, "%s_bucket%s %d\n", f.name, formatLabels(s.labels, "le", formatFloat(upper)), h.Cumulative[i])
+				}
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", f.name, formatLabels(s.labels, "le", "+Inf"), h.Count)
+				fmt.Fprintf(bw, "%s_sum%s %s\n", f.name, formatLabels(s.labels, "", ""), formatFloat(h.Sum))
+				fmt.Fprintf(bw, "%s_count%s %d\n", f.name, formatLabels(s.labels, "", ""), h.Count)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Handler 返回输出 Prometheus 文本格式的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// formatLabels 格式化标签，extraName 非空时追加一个额外标签（如 le）
+func formatLabels(labels Labels, extraName, extraValue string) string {
+	if len(labels) == 0 && extraName == "" {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels)+1)
+	for _, name := range labels.names() {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(labels[name])))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", extraName, extraValue))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatFloat 格式化浮点数
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabelValue 转义标签值
+func escapeLabelValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// escapeHelp 转义帮助文本
+func escapeHelp(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// DefaultRegistry 全局指标注册中心
+var DefaultRegistry = NewRegistry()
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Server 本地指标 HTTP 服务（GET /metrics）
+type Server struct {
+	addr     string
+	registry *Registry
+	server   *http.Server
+}
+
+// NewServer 创建指标服务，registry 为 nil 时使用 DefaultRegistry
+func NewServer(addr string, registry *Registry) *Server {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	return &Server{
+		addr:     addr,
+		registry: registry,
+	}
+}
+
+// Start 监听地址并在后台提供服务
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", s.addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.registry.Handler())
+
+	s.server = &http.Server{
+		Handler: mux,
+	}
+
+	go s.server.Serve(listener)
+
+	return nil
+}
+
+// Stop 停止服务
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+// Addr 返回监听地址
+func (s *Server) Addr() string {
+	return s.addr
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常调用
+	BreakerOpen     BreakerState = "open"      // 熔断中，跳过调用
+	BreakerHalfOpen BreakerState = "half_open" // 冷却结束，试探调用
+)
+
+// 默认熔断参数
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// breaker 插件熔断器
+//
+// 连续失败达到阈值后熔断，冷却期内跳过插件调用；冷却结束后试探调用一次，
+// 成功则恢复，失败则重新熔断。每个插件的调用在单个协程中串行进行，
+// 因此半开状态下不会有并发的试探调用。
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int // 连续失败次数
+	openedAt time.Time
+}
+
+// newBreaker 创建熔断器，参数非正时使用默认值
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// allow 返回本次是否调用插件；状态发生变化时返回新状态，否则返回空字符串
+func (b *breaker) allow() (bool, BreakerState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return true, ""
+	}
+
+	if time.Since(b.openedAt) < b.cooldown {
+		return false, ""
+	}
+
+	b.state = BreakerHalfOpen
+	return true, BreakerHalfOpen
+}
+
+// success 记录一次成功调用，返回状态变化
+func (b *breaker) success() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state == BreakerClosed {
+		return ""
+	}
+
+	b.state = BreakerClosed
+	return BreakerClosed
+}
+
+// failure 记录一次失败调用，返回状态变化
+func (b *breaker) failure() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == BreakerOpen {
+		return ""
+	}
+	if b.state == BreakerClosed && b.failures < b.threshold {
+		return ""
+	}
+
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	return BreakerOpen
+}
+
+// current 返回当前状态
+func (b *breaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// FilterErrorPolicy 过滤器无法给出结果（panic、出错、熔断）时的处理策略
+type FilterErrorPolicy string
+
+const (
+	FilterErrorBlock FilterErrorPolicy = "block" // 拦截消息
+	FilterErrorPass  FilterErrorPolicy = "pass"  // 放行消息
+)
+
+// DefaultFilterErrorPolicy 默认拦截：屏蔽、关键词等过滤器出错时不应把本该拦截的消息放出去
+const DefaultFilterErrorPolicy = FilterErrorBlock
+
+// ParseFilterErrorPolicy 解析过滤器出错策略，空字符串返回默认策略
+func ParseFilterErrorPolicy(s string) (FilterErrorPolicy, error) {
+	switch FilterErrorPolicy(s) {
+	case "":
+		return DefaultFilterErrorPolicy, nil
+	case FilterErrorBlock, FilterErrorPass:
+		return FilterErrorPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid filter error policy: %s", s)
+}
+
+// FilterConfig 过滤器调用策略，零值字段使用默认值
+type FilterConfig struct {
+	OnError FilterErrorPolicy // 出错或熔断时拦截还是放行
+}
+
+// withDefaults 返回填充默认值后的策略
+func (c FilterConfig) withDefaults() FilterConfig {
+	if c.OnError == "" {
+		c.OnError = DefaultFilterErrorPolicy
+	}
+	return c
+}
+
+// filter 在熔断器保护下调用过滤器，返回消息是否通过
+//
+// 过滤器 panic、返回错误（见 plugin.FallibleFilter）或处于熔断中时按其 OnError 策略拦截或放行。
+func (p *Pipeline) filter(f *filterStage, msg *models.Message) bool {
+	var passed bool
+	called, err := p.invoke(f.pluginState, f.filter, func() error {
+		if fallible, ok := f.filter.(plugin.FallibleFilter); ok {
+			var err error
+			passed, err = fallible.TryFilter(p.ctx, msg)
+			return err
+		}
+		passed = f.filter.Filter(p.ctx, msg)
+		return nil
+	})
+	if !called || err != nil {
+		return f.config.OnError == FilterErrorPass
+	}
+
+	if !passed {
+		f.metrics.out.Inc()
+	}
+	return passed
+}
@@ -4,34 +4,86 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
 
+// DefaultShutdownTimeout 关闭单个管道时排空队列的时间上限
+//
+// 超时后放弃剩余消息：不响应 ctx 的消费者可能一直不返回，无限等待会让断开连接和热重载卡住。
+const DefaultShutdownTimeout = 10 * time.Second
+
 // Manager 管道管理器
+//
+// 管道按 Input 组成有向无环图：Manager 只向根管道分发消息，
+// 其余管道由上游管道在阶段处理后转发。管道按添加顺序保存，
+// 上游总是先于下游添加，因此该顺序即拓扑顺序。
 type Manager struct {
 	pipelines []*Pipeline
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
+
+	// 管道内插件的生命周期管理器
+	plugins *plugin.PluginManager
+
+	// reconcileMu 串行化 Reconcile 与 Shutdown
+	reconcileMu sync.Mutex
 }
 
-// NewManager 创建新的管道管理器
+// NewManager 创建新的管道管理器，并启动插件健康检查
 func NewManager() *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
+
+	plugins := plugin.NewPluginManager(nil)
+	plugins.StartHealthChecks(plugin.DefaultHealthInterval)
+
 	return &Manager{
 		pipelines: make([]*Pipeline, 0),
 		ctx:       ctx,
 		cancel:    cancel,
+		plugins:   plugins,
 	}
 }
 
-// AddPipeline 添加管道
-func (m *Manager) AddPipeline(p *Pipeline) {
+// Plugins 返回插件生命周期管理器，构建管道时通过它创建插件
+func (m *Manager) Plugins() *plugin.PluginManager {
+	return m.plugins
+}
+
+// AddPipeline 添加管道，配置了 Input 的管道会挂接到已添加的上游管道
+func (m *Manager) AddPipeline(p *Pipeline) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+
+	if m.find(p.Name()) != nil {
+		return fmt.Errorf("pipeline %s already exists", p.Name())
+	}
+
+	if p.Input() != "" {
+		parent := m.find(p.Input())
+		if parent == nil {
+			return fmt.Errorf("pipeline %s: input %s not found", p.Name(), p.Input())
+		}
+		parent.AddDownstream(p)
+	}
+
 	m.pipelines = append(m.pipelines, p)
 
+	return nil
+}
+
+// find 按名称查找管道（调用方需持有锁）
+func (m *Manager) find(name string) *Pipeline {
+	for _, p := range m.pipelines {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
 }
 
 // GetPipeline 获取管道
@@ -39,10 +91,8 @@ func (m *Manager) GetPipeline(name string) (*Pipeline, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	for _, p := range m.pipelines {
-		if p.Name() == name {
-			return p, nil
-		}
+	if p := m.find(name); p != nil {
+		return p, nil
 	}
 
 	return nil, fmt.Errorf("pipeline %s not found", name)
@@ -58,13 +108,20 @@ func (m *Manager) GetAllPipelines() []*Pipeline {
 	return pipelines
 }
 
-// RemovePipeline 移除管道
+// RemovePipeline 移除管道并从上游摘除，仍有下游管道时拒绝移除
 func (m *Manager) RemovePipeline(name string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for i, p := range m.pipelines {
 		if p.Name() == name {
+			if downstreams := p.Downstreams(); len(downstreams) > 0 {
+				return fmt.Errorf("pipeline %s still feeds %d downstream pipelines", name, len(downstreams))
+			}
+
+			if parent := m.find(p.Input()); parent != nil {
+				parent.RemoveDownstream(p)
+			}
 			m.pipelines = append(m.pipelines[:i], m.pipelines[i+1:]...)
 
 			return nil
@@ -74,24 +131,24 @@ func (m *Manager) RemovePipeline(name string) error {
 	return fmt.Errorf("pipeline %s not found", name)
 }
 
-// Dispatch 分发消息到所有管道（完全异步，不等待）
+// Dispatch 分发消息到所有根管道的入口队列
+//
+// 入队按各管道的溢出策略进行，处理过程在管道自己的协程中异步完成。
+// 下游管道的消息由上游管道转发，不在此处分发。
 func (m *Manager) Dispatch(ctx context.Context, msg *models.Message) {
 	m.mu.RLock()
 	pipelines := make([]*Pipeline, len(m.pipelines))
 	copy(pipelines, m.pipelines)
 	m.mu.RUnlock()
 
-	// 并行处理所有管道，完全异步
 	for _, pipeline := range pipelines {
-		if !pipeline.IsEnabled() {
+		if pipeline.Input() != "" || !pipeline.IsEnabled() {
 			continue
 		}
 
-		go func(p *Pipeline) {
-			if err := p.Process(ctx, msg); err != nil {
-
-			}
-		}(pipeline)
+		if err := pipeline.Process(ctx, msg); err != nil {
+			event.Publish(event.MessageDropped(pipeline.Name(), err.Error()))
+		}
 	}
 }
 
@@ -128,6 +185,7 @@ func (m *Manager) GetStats() map[string]interface{} {
 	stats["total_pipelines"] = len(m.pipelines)
 
 	enabledCount := 0
+	droppedCount := 0
 	pipelineStats := make([]map[string]interface{}, 0)
 
 	for _, p := range m.pipelines {
@@ -135,14 +193,20 @@ func (m *Manager) GetStats() map[string]interface{} {
 			enabledCount++
 		}
 
+		pStats := p.GetStats()
+		droppedCount += pStats["dropped"]
+
 		pipelineStats = append(pipelineStats, map[string]interface{}{
 			"name":    p.Name(),
+			"input":   p.Input(),
 			"enabled": p.IsEnabled(),
-			"stats":   p.GetStats(),
+			"stats":   pStats,
+			"plugins": p.GetPluginStats(),
 		})
 	}
 
 	stats["enabled_pipelines"] = enabledCount
+	stats["dropped_messages"] = droppedCount
 	stats["pipelines"] = pipelineStats
 
 	return stats
@@ -150,18 +214,32 @@ func (m *Manager) GetStats() map[string]interface{} {
 
 // Shutdown 关闭管道管理器
 func (m *Manager) Shutdown() {
+	// 等待进行中的 Reconcile 完成
+	m.reconcileMu.Lock()
+	defer m.reconcileMu.Unlock()
 
 	// 先取消 context
 	m.cancel()
 
-	// 关闭所有 pipeline（每个 pipeline 会等待自己的任务完成）
+	// 按拓扑顺序关闭所有 pipeline（上游先排空并转发给下游，再关闭下游）
 	ctx := context.Background()
 	m.mu.Lock()
 	for _, p := range m.pipelines {
-		if err := p.Shutdown(ctx); err != nil {
-
-		}
+		shutdownPipeline(ctx, p)
 	}
 	m.mu.Unlock()
 
+	// 停止未挂到任何管道的剩余实例，并结束健康检查
+	stopCtx, cancel := context.WithTimeout(ctx, DefaultShutdownTimeout)
+	defer cancel()
+	m.plugins.StopAll(stopCtx)
+}
+
+// shutdownPipeline 在 DefaultShutdownTimeout 内排空并关闭管道
+func shutdownPipeline(ctx context.Context, p *Pipeline) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultShutdownTimeout)
+	defer cancel()
+
+	// 插件停止失败已由 pipeline 发布到事件总线
+	p.Shutdown(ctx)
 }
@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"github.com/xifan2333/dmnotifier/internal/metrics"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+)
+
+// pipelineMetrics 管道级运行时指标
+type pipelineMetrics struct {
+	in          *metrics.Counter   // 进入管道的消息数
+	filtered    *metrics.Counter   // 被过滤器拦截的消息数
+	transformed *metrics.Counter   // 完成全部转换的消息数
+	consumed    *metrics.Counter   // 被消费者成功处理的消息数（按消费者计）
+	failed      *metrics.Counter   // 转换或消费失败的消息数
+	dropped     *metrics.Counter   // 因队列溢出或关闭被丢弃的消息数
+	latency     *metrics.Histogram // 过滤与转换阶段耗时
+}
+
+// newPipelineMetrics 创建管道指标
+func newPipelineMetrics(registry *metrics.Registry, name string) *pipelineMetrics {
+	labels := metrics.Labels{"pipeline": name}
+	return &pipelineMetrics{
+		in:          registry.Counter("dmnotifier_pipeline_messages_in_total", "Messages accepted by the pipeline.", labels),
+		filtered:    registry.Counter("dmnotifier_pipeline_messages_filtered_total", "Messages rejected by a filter.", labels),
+		transformed: registry.Counter("dmnotifier_pipeline_messages_transformed_total", "Messages that passed all transforms.", labels),
+		consumed:    registry.Counter("dmnotifier_pipeline_messages_consumed_total", "Messages successfully consumed, counted per consumer.", labels),
+		failed:      registry.Counter("dmnotifier_pipeline_messages_failed_total", "Messages that failed in a transform or consumer.", labels),
+		dropped:     registry.Counter("dmnotifier_pipeline_messages_dropped_total", "Messages dropped by queue overflow or shutdown.", labels),
+		latency:     registry.Histogram("dmnotifier_pipeline_processing_seconds", "Time spent in filters and transforms per message.", nil, labels),
+	}
+}
+
+// pluginMetrics 插件级运行时指标
+type pluginMetrics struct {
+	stage    plugin.PluginType
+	name     string
+	instance string
+	in       *metrics.Counter   // 调用次数
+	out      *metrics.Counter   // 过滤器：拦截数；转换器：成功数；消费者：成功数
+	failed   *metrics.Counter   // 失败次数（含 panic）
+	skipped  *metrics.Counter   // 熔断期间跳过的调用次数
+	trips    *metrics.Counter   // 熔断次数
+	dropped  *metrics.Counter   // 消费者队列溢出或熔断丢弃数
+	latency  *metrics.Histogram // 单次调用耗时（消费者按每次尝试计）
+
+	// 仅消费者
+	retries      *metrics.Counter // 重试次数
+	deadLettered *metrics.Counter // 写入死信的消息数
+}
+
+// newPluginMetrics 创建插件指标，instance 区分同一管道中的同名插件
+func newPluginMetrics(registry *metrics.Registry, pipelineName, instance string, p plugin.Plugin) *pluginMetrics {
+	labels := metrics.Labels{
+		"pipeline": pipelineName,
+		"plugin":   p.Name(),
+		"instance": instance,
+		"stage":    string(p.Type()),
+	}
+
+	m := &pluginMetrics{
+		stage:    p.Type(),
+		name:     p.Name(),
+		instance: instance,
+		in:       registry.Counter("dmnotifier_plugin_messages_in_total", "Messages handed to the plugin.", labels),
+		failed:   registry.Counter("dmnotifier_plugin_messages_failed_total", "Plugin calls that returned an error or panicked.", labels),
+		skipped:  registry.Counter("dmnotifier_plugin_messages_skipped_total", "Plugin calls skipped while the circuit breaker was open.", labels),
+		trips:    registry.Counter("dmnotifier_plugin_breaker_trips_total", "Times the plugin circuit breaker opened.", labels),
+		latency:  registry.Histogram("dmnotifier_plugin_duration_seconds", "Time spent in a single plugin call.", nil, labels),
+	}
+
+	switch p.Type() {
+	case plugin.TypeFilter:
+		m.out = registry.Counter("dmnotifier_plugin_messages_filtered_total", "Messages rejected by the filter.", labels)
+	case plugin.TypeTransform:
+		m.out = registry.Counter("dmnotifier_plugin_messages_transformed_total", "Messages transformed by the plugin.", labels)
+	default:
+		m.out = registry.Counter("dmnotifier_plugin_messages_consumed_total", "Messages consumed by the plugin.", labels)
+		m.dropped = registry.Counter("dmnotifier_plugin_messages_dropped_total", "Messages dropped before reaching the consumer.", labels)
+		m.retries = registry.Counter("dmnotifier_plugin_retries_total", "Consume calls retried after a retryable error.", labels)
+		m.deadLettered = registry.Counter("dmnotifier_plugin_messages_dead_lettered_total", "Messages written to the dead letter sink.", labels)
+	}
+
+	return m
+}
+
+// stats 返回插件统计快照
+func (m *pluginMetrics) stats() map[string]interface{} {
+	latency := m.latency.Snapshot()
+	stats := map[string]interface{}{
+		"plugin":         m.name,
+		"instance":       m.instance,
+		"stage":          string(m.stage),
+		"in":             m.in.Value(),
+		"failed":         m.failed.Value(),
+		"skipped":        m.skipped.Value(),
+		"trips":          m.trips.Value(),
+		"avg_latency_ms": latency.Mean() * 1000,
+		"p95_latency_ms": latency.Quantile(0.95) * 1000,
+	}
+
+	switch m.stage {
+	case plugin.TypeFilter:
+		stats["filtered"] = m.out.Value()
+	case plugin.TypeTransform:
+		stats["transformed"] = m.out.Value()
+	default:
+		stats["consumed"] = m.out.Value()
+		stats["dropped"] = m.dropped.Value()
+		stats["retries"] = m.retries.Value()
+		stats["dead_lettered"] = m.deadLettered.Value()
+	}
+
+	return stats
+}
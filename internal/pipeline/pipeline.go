@@ -2,38 +2,172 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/metrics"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
 
+// DefaultFlushInterval 实现 plugin.Flusher 的转换器未给出有效间隔时使用的默认值
+const DefaultFlushInterval = time.Second
+
 // Pipeline 消息处理管道
+//
+// 每个管道拥有一个有界入口队列和一个阶段协程（依次执行过滤器与转换器），
+// 每个消费者拥有独立的有界队列和单个工作协程，保证同一消费者内的消息顺序。
+//
+// 管道可以挂接下游管道组成有向无环图：阶段处理后的消息同时分发给本管道的
+// 消费者和所有下游管道，多个分支共享同一条消息，因此转换器不应原地修改消息。
 type Pipeline struct {
-	name       string
-	enabled    bool
-	filters    []plugin.FilterPlugin
-	transforms []plugin.TransformPlugin
-	consumers  []plugin.ConsumerPlugin
-	mu         sync.RWMutex
-	wg         sync.WaitGroup // 追踪正在处理的消费者任务
+	name        string
+	input       string // 上游管道名称，为空表示根管道
+	hash        string // 构建该管道的配置指纹
+	enabled     bool
+	filters     []*filterStage
+	transforms  []*transformStage
+	consumers   []*consumerWorker
+	downstreams []*Pipeline
+	mu          sync.RWMutex
+
+	// 运行时指标
+	registry *metrics.Registry
+	metrics  *pipelineMetrics
+
+	// 队列配置
+	queueSize int
+	overflow  OverflowPolicy
+	ingress   *queue
+
+	// 熔断配置
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	// 死信接收器，为 nil 时失败消息只发布事件
+	deadLetters deadletter.Sink
+
+	// 插件生命周期管理器，为 nil 时由管道直接停止插件
+	plugins *plugin.PluginManager
+
+	// 添加了实现 plugin.Flusher 的转换器，阶段协程据此调整定时器
+	flushersChanged chan struct{}
+
+	// 上下文控制
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stageWg    sync.WaitGroup // 追踪阶段协程
+	consumerWg sync.WaitGroup // 追踪消费者协程
+}
+
+// pluginState 插件在管道中的运行时状态
+type pluginState struct {
+	id      string // 插件在 PluginManager 中的实例 ID，未受管理时为空
+	metrics *pluginMetrics
+	breaker *breaker
+}
+
+// filterStage 过滤阶段
+type filterStage struct {
+	*pluginState
+	filter plugin.FilterPlugin
+	config FilterConfig
+}
+
+// transformStage 转换阶段
+type transformStage struct {
+	*pluginState
+	transform plugin.TransformPlugin
+	batch     plugin.BatchTransformer // 未实现时为 nil
+
+	// 定时输出，未实现 plugin.Flusher 时 flusher 为 nil；只在阶段协程中访问
+	flusher       plugin.Flusher
+	flushInterval time.Duration
+	lastFlush     time.Time
+}
+
+// consumerWorker 消费者工作单元
+type consumerWorker struct {
+	*pluginState
+	consumer plugin.ConsumerPlugin
+	config   ConsumerConfig
+	queue    *queue
+
+	// 超时后仍在运行的 Consume 调用，返回前不开始新的调用；只在工作协程中访问
+	abandoned <-chan error
 }
 
 // PipelineConfig 管道配置
 type PipelineConfig struct {
-	Name    string
-	Enabled bool
+	Name      string
+	Input     string // 上游管道名称，为空时直接接收 Manager 分发的消息
+	Hash      string // 配置指纹，Manager.Reconcile 据此判断是否需要重建
+	Enabled   bool
+	QueueSize int               // 入口队列与每个消费者队列的长度
+	Overflow  OverflowPolicy    // 队列溢出策略
+	Metrics   *metrics.Registry // 指标注册中心，为 nil 时使用 metrics.DefaultRegistry
+
+	BreakerThreshold int           // 插件连续失败多少次后熔断
+	BreakerCooldown  time.Duration // 熔断后多久试探恢复
+
+	DeadLetter deadletter.Sink // 重试耗尽的消息写入此处，可为 nil
+
+	// Plugins 创建插件的生命周期管理器，可为 nil；管道关闭时通过它停止插件，
+	// 并把熔断状态上报为实例降级
+	Plugins *plugin.PluginManager
 }
 
-// NewPipeline 创建新的管道
+// NewPipeline 创建新的管道并启动阶段协程
 func NewPipeline(config PipelineConfig) *Pipeline {
-	return &Pipeline{
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Overflow == "" {
+		config.Overflow = DefaultOverflow
+	}
+	if config.Metrics == nil {
+		config.Metrics = metrics.DefaultRegistry
+	}
+	if config.BreakerThreshold <= 0 {
+		config.BreakerThreshold = DefaultBreakerThreshold
+	}
+	if config.BreakerCooldown <= 0 {
+		config.BreakerCooldown = DefaultBreakerCooldown
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &Pipeline{
 		name:       config.Name,
+		input:      config.Input,
+		hash:       config.Hash,
 		enabled:    config.Enabled,
-		filters:    make([]plugin.FilterPlugin, 0),
-		transforms: make([]plugin.TransformPlugin, 0),
-		consumers:  make([]plugin.ConsumerPlugin, 0),
+		filters:    make([]*filterStage, 0),
+		transforms: make([]*transformStage, 0),
+		consumers:  make([]*consumerWorker, 0),
+		registry:   config.Metrics,
+		metrics:    newPipelineMetrics(config.Metrics, config.Name),
+		queueSize:  config.QueueSize,
+		overflow:   config.Overflow,
+		ingress:    newQueue(config.QueueSize, config.Overflow),
+		ctx:        ctx,
+		cancel:     cancel,
+
+		breakerThreshold: config.BreakerThreshold,
+		breakerCooldown:  config.BreakerCooldown,
+		deadLetters:      config.DeadLetter,
+		plugins:          config.Plugins,
+		flushersChanged:  make(chan struct{}, 1),
 	}
+
+	p.stageWg.Add(1)
+	go p.runStages()
+
+	return p
 }
 
 // Name 返回管道名称
@@ -41,6 +175,16 @@ func (p *Pipeline) Name() string {
 	return p.name
 }
 
+// Input 返回上游管道名称，根管道返回空字符串
+func (p *Pipeline) Input() string {
+	return p.input
+}
+
+// Hash 返回构建该管道的配置指纹
+func (p *Pipeline) Hash() string {
+	return p.hash
+}
+
 // IsEnabled 返回管道是否启用
 func (p *Pipeline) IsEnabled() bool {
 	p.mu.RLock()
@@ -55,122 +199,612 @@ func (p *Pipeline) SetEnabled(enabled bool) {
 	p.enabled = enabled
 }
 
-// AddFilter 添加过滤器
+// AddFilter 以默认调用策略添加过滤器
 func (p *Pipeline) AddFilter(f plugin.FilterPlugin) {
+	p.AddFilterWithConfig(f, FilterConfig{})
+}
+
+// AddFilterWithConfig 添加过滤器
+func (p *Pipeline) AddFilterWithConfig(f plugin.FilterPlugin, config FilterConfig) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.filters = append(p.filters, f)
-
+	p.filters = append(p.filters, &filterStage{
+		pluginState: p.newPluginState(f, len(p.filters)),
+		filter:      f,
+		config:      config.withDefaults(),
+	})
 }
 
 // AddTransform 添加转换器
+//
+// 转换器实现 plugin.BatchTransformer 时按批量方式调用，实现 plugin.Flusher 时由阶段协程定时调用 Flush。
 func (p *Pipeline) AddTransform(t plugin.TransformPlugin) {
+	p.mu.RLock()
+	position := len(p.transforms)
+	p.mu.RUnlock()
+
+	stage := &transformStage{
+		pluginState: p.newPluginState(t, position),
+		transform:   t,
+	}
+	stage.batch, _ = t.(plugin.BatchTransformer)
+	if flusher, ok := t.(plugin.Flusher); ok {
+		stage.flusher = flusher
+		stage.flushInterval = flusher.FlushInterval()
+		if stage.flushInterval <= 0 {
+			stage.flushInterval = DefaultFlushInterval
+		}
+		stage.lastFlush = time.Now()
+	}
+
 	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.transforms = append(p.transforms, t)
+	p.transforms = append(p.transforms, stage)
+	p.mu.Unlock()
 
+	if stage.flusher != nil {
+		select {
+		case p.flushersChanged <- struct{}{}:
+		default:
+		}
+	}
 }
 
-// AddConsumer 添加消费者
+// AddConsumer 以默认调用策略添加消费者
 func (p *Pipeline) AddConsumer(c plugin.ConsumerPlugin) {
+	p.AddConsumerWithConfig(c, ConsumerConfig{})
+}
+
+// AddConsumerWithConfig 添加消费者，并为其启动独立的工作协程
+func (p *Pipeline) AddConsumerWithConfig(c plugin.ConsumerPlugin, config ConsumerConfig) {
+	p.mu.RLock()
+	position := len(p.consumers)
+	p.mu.RUnlock()
+
+	w := &consumerWorker{
+		pluginState: p.newPluginState(c, position),
+		consumer:    c,
+		config:      config.withDefaults(),
+		queue:       newQueue(p.queueSize, p.overflow),
+	}
+
+	p.mu.Lock()
+	p.consumers = append(p.consumers, w)
+	p.mu.Unlock()
+
+	p.consumerWg.Add(1)
+	go p.runConsumer(w)
+}
+
+// AddDownstream 挂接下游管道，阶段处理后的消息会转发给它
+func (p *Pipeline) AddDownstream(d *Pipeline) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.downstreams = append(p.downstreams, d)
+}
+
+// RemoveDownstream 摘除下游管道
+//
+// 重新分配切片，避免修改阶段协程正在遍历的快照。
+func (p *Pipeline) RemoveDownstream(d *Pipeline) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.consumers = append(p.consumers, c)
 
+	downstreams := make([]*Pipeline, 0, len(p.downstreams))
+	for _, existing := range p.downstreams {
+		if existing != d {
+			downstreams = append(downstreams, existing)
+		}
+	}
+	p.downstreams = downstreams
+}
+
+// ReplaceDownstream 把下游管道 old 替换为 d，old 不存在时追加 d
+func (p *Pipeline) ReplaceDownstream(old, d *Pipeline) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	downstreams := make([]*Pipeline, 0, len(p.downstreams)+1)
+	replaced := false
+	for _, existing := range p.downstreams {
+		if existing == old {
+			existing, replaced = d, true
+		}
+		downstreams = append(downstreams, existing)
+	}
+	if !replaced {
+		downstreams = append(downstreams, d)
+	}
+	p.downstreams = downstreams
 }
 
-// Process 处理消息
+// Downstreams 返回下游管道列表
+func (p *Pipeline) Downstreams() []*Pipeline {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	downstreams := make([]*Pipeline, len(p.downstreams))
+	copy(downstreams, p.downstreams)
+	return downstreams
+}
+
+// Process 将消息放入管道入口队列
+//
+// 入队遵循管道的溢出策略；阻塞策略下 ctx 用于取消等待。
+// 实际处理在管道自己的协程中进行，不使用调用方的 ctx。
 func (p *Pipeline) Process(ctx context.Context, msg *models.Message) error {
 	// 检查管道是否启用
 	if !p.IsEnabled() {
 		return nil
 	}
 
+	p.metrics.in.Inc()
+
+	dropped, err := p.ingress.push(ctx, msg)
+	if err != nil {
+		p.metrics.dropped.Inc()
+	}
+	if dropped {
+		p.metrics.dropped.Inc()
+		event.Publish(event.MessageDropped(p.name, fmt.Sprintf("ingress queue full (%s)", p.overflow)))
+	}
+	return err
+}
+
+// runStages 阶段协程：按顺序执行过滤器和转换器，再分发给各消费者队列和下游管道
+//
+// 有转换器实现 plugin.Flusher 时同时定时调用其 Flush；入口队列关闭后最后一次调用 Flush 输出全部暂存消息。
+func (p *Pipeline) runStages() {
+	defer p.stageWg.Done()
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	messages := p.ingress.messages()
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				if p.ctx.Err() == nil {
+					p.flush(true)
+				}
+				return
+			}
+			if p.ctx.Err() != nil {
+				continue
+			}
+
+			p.processMessage(msg)
+
+		case <-tick:
+			p.flush(false)
+
+		case <-p.flushersChanged:
+			if ticker != nil {
+				ticker.Stop()
+			}
+			ticker = time.NewTicker(p.flushTick())
+			tick = ticker.C
+		}
+	}
+}
+
+// flushTick 返回阶段协程检查 Flusher 的间隔（各转换器间隔的最小值）
+func (p *Pipeline) flushTick() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	tick := time.Duration(0)
+	for _, t := range p.transforms {
+		if t.flusher != nil && (tick == 0 || t.flushInterval < tick) {
+			tick = t.flushInterval
+		}
+	}
+	if tick == 0 {
+		tick = DefaultFlushInterval
+	}
+	return tick
+}
+
+// flush 调用到期的 Flusher（final 时为全部），输出的消息经过其后的转换器再分发
+func (p *Pipeline) flush(final bool) {
 	p.mu.RLock()
-	filters := p.filters
 	transforms := p.transforms
 	consumers := p.consumers
+	downstreams := p.downstreams
 	p.mu.RUnlock()
 
-	// 阶段 1: 通过所有过滤器
-	for _, filter := range filters {
-		if !filter.Filter(ctx, msg) {
+	now := time.Now()
+	for i, t := range transforms {
+		if t.flusher == nil || (!final && now.Sub(t.lastFlush) < t.flushInterval) {
+			continue
+		}
+		t.lastFlush = now
+
+		var flushed []*models.Message
+		called, err := p.guard(t.pluginState, t.transform, func() error {
+			var err error
+			flushed, err = t.flusher.Flush(p.ctx, final)
+			return err
+		})
+		if !called || err != nil || len(flushed) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		outputs := p.applyTransforms(transforms[i+1:], flushed)
+		p.metrics.latency.Since(start)
 
-			return nil // 被过滤，不继续处理
+		for _, out := range outputs {
+			p.dispatch(consumers, downstreams, out)
 		}
 	}
+}
 
-	// 阶段 2: 应用所有转换器
-	transformedMsg := msg
-	for _, transform := range transforms {
-		var err error
-		transformedMsg, err = transform.Transform(ctx, transformedMsg)
-		if err != nil {
+// processMessage 处理单条消息
+func (p *Pipeline) processMessage(msg *models.Message) {
+	p.mu.RLock()
+	filters := p.filters
+	transforms := p.transforms
+	consumers := p.consumers
+	downstreams := p.downstreams
+	p.mu.RUnlock()
 
-			return err
+	start := time.Now()
+
+	// 阶段 1: 通过所有过滤器（熔断或 panic 的过滤器按其出错策略处理）
+	for _, f := range filters {
+		if !p.filter(f, msg) {
+			p.metrics.filtered.Inc()
+			p.metrics.latency.Since(start)
+			return // 被过滤，不继续处理
 		}
 	}
 
-	// 阶段 3: 分发给所有消费者（并行且异步，不等待完成）
-	if len(consumers) == 0 {
-		return nil
+	// 阶段 2: 应用所有转换器（可能暂存消息或输出多条）
+	outputs := p.applyTransforms(transforms, []*models.Message{msg})
+	p.metrics.latency.Since(start)
+
+	// 阶段 3、4: 分发
+	for _, out := range outputs {
+		p.dispatch(consumers, downstreams, out)
 	}
+}
 
-	for _, consumer := range consumers {
-		p.wg.Add(1)
-		go func(c plugin.ConsumerPlugin, msg *models.Message) {
-			defer p.wg.Done()
-			if err := c.Consume(ctx, msg); err != nil {
+// applyTransforms 依次应用转换器，熔断中的转换器直接放行，转换失败的消息被丢弃
+func (p *Pipeline) applyTransforms(transforms []*transformStage, msgs []*models.Message) []*models.Message {
+	for _, t := range transforms {
+		next := make([]*models.Message, 0, len(msgs))
+		for _, msg := range msgs {
+			results, called, err := p.transform(t, msg)
+			if !called {
+				next = append(next, msg)
+				continue
+			}
 
+			if err != nil {
+				p.metrics.failed.Inc()
+				continue
 			}
-		}(consumer, transformedMsg)
+			t.metrics.out.Inc()
+			next = append(next, results...)
+		}
+
+		msgs = next
+		if len(msgs) == 0 {
+			return nil
+		}
 	}
 
-	// 立即返回，不等待消费者完成
-	return nil
+	p.metrics.transformed.Add(uint64(len(msgs)))
+	return msgs
+}
+
+// transform 调用单个转换器，返回零条或多条输出
+func (p *Pipeline) transform(t *transformStage, msg *models.Message) (results []*models.Message, called bool, err error) {
+	called, err = p.invoke(t.pluginState, t.transform, func() error {
+		if t.batch != nil {
+			var err error
+			results, err = t.batch.TransformBatch(p.ctx, msg)
+			return err
+		}
+
+		result, err := t.transform.Transform(p.ctx, msg)
+		if result != nil {
+			results = []*models.Message{result}
+		}
+		return err
+	})
+	return results, called, err
+}
+
+// dispatch 把处理后的消息放入各消费者队列，并转发给下游管道
+func (p *Pipeline) dispatch(consumers []*consumerWorker, downstreams []*Pipeline, msg *models.Message) {
+	// 放入各消费者队列
+	for _, w := range consumers {
+		dropped, err := w.queue.push(p.ctx, msg)
+		if dropped || err != nil {
+			w.metrics.dropped.Inc()
+			p.metrics.dropped.Inc()
+		}
+		if dropped {
+			event.Publish(event.MessageDropped(p.pluginSource(w.consumer), fmt.Sprintf("consumer queue full (%s)", p.overflow)))
+		}
+		if err != nil {
+			event.Publish(event.MessageDropped(p.pluginSource(w.consumer), err.Error()))
+		}
+	}
+
+	// 转发给下游管道（下游按自己的溢出策略入队）
+	for _, d := range downstreams {
+		if err := d.Process(p.ctx, msg); err != nil {
+			event.Publish(event.MessageDropped(d.Name(), err.Error()))
+		}
+	}
 }
 
-// GetStats 获取管道统计信息
+// runConsumer 消费者协程：按入队顺序逐条消费
+func (p *Pipeline) runConsumer(w *consumerWorker) {
+	defer p.consumerWg.Done()
+
+	for msg := range w.queue.messages() {
+		if p.ctx.Err() != nil {
+			continue
+		}
+
+		// 管道关闭时不再等待超时的调用
+		if !p.awaitAbandoned(w) {
+			w.metrics.dropped.Inc()
+			p.metrics.dropped.Inc()
+			continue
+		}
+
+		// 熔断中的消费者跳过消息
+		attempts, err := p.consume(w, msg)
+		if attempts == 0 {
+			w.metrics.dropped.Inc()
+			p.metrics.dropped.Inc()
+			continue
+		}
+
+		if err != nil {
+			p.metrics.failed.Inc()
+			p.deadLetter(w, msg, attempts, err)
+			continue
+		}
+		w.metrics.out.Inc()
+		p.metrics.consumed.Inc()
+	}
+}
+
+// GetStats 获取管道统计信息（消息计数为同名管道的累计值）
 func (p *Pipeline) GetStats() map[string]int {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
+	queued := p.ingress.len()
+	for _, w := range p.consumers {
+		queued += w.queue.len()
+	}
+
 	return map[string]int{
-		"filters":    len(p.filters),
-		"transforms": len(p.transforms),
-		"consumers":  len(p.consumers),
+		"filters":     len(p.filters),
+		"transforms":  len(p.transforms),
+		"consumers":   len(p.consumers),
+		"downstreams": len(p.downstreams),
+		"queued":      queued,
+		"in":          int(p.metrics.in.Value()),
+		"filtered":    int(p.metrics.filtered.Value()),
+		"transformed": int(p.metrics.transformed.Value()),
+		"consumed":    int(p.metrics.consumed.Value()),
+		"failed":      int(p.metrics.failed.Value()),
+		"dropped":     int(p.metrics.dropped.Value()),
+	}
+}
+
+// GetPluginStats 获取管道内各插件的统计信息
+func (p *Pipeline) GetPluginStats() []map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := make([]map[string]interface{}, 0, len(p.filters)+len(p.transforms)+len(p.consumers))
+	for _, f := range p.filters {
+		stats = append(stats, f.stats())
+	}
+	for _, t := range p.transforms {
+		stats = append(stats, t.stats())
 	}
+	for _, w := range p.consumers {
+		stats = append(stats, w.stats())
+	}
+	return stats
 }
 
-// Shutdown 关闭管道，停止所有插件
+// Shutdown 关闭管道，排空队列后停止所有插件
+//
+// 若 ctx 先于排空完成被取消，则放弃剩余消息。
 func (p *Pipeline) Shutdown(ctx context.Context) error {
+	// 关闭入口队列，等待阶段协程把已入队消息分发完毕
+	p.ingress.close()
+	if err := waitGroup(ctx, &p.stageWg); err != nil {
+		p.cancel()
+		p.stageWg.Wait()
+	}
 
-	// 等待所有正在处理的消费者任务完成
-	p.wg.Wait()
+	// 关闭消费者队列，等待消费者处理完剩余消息
+	p.mu.RLock()
+	for _, w := range p.consumers {
+		w.queue.close()
+	}
+	p.mu.RUnlock()
+
+	if err := waitGroup(ctx, &p.consumerWg); err != nil {
+		p.cancel()
+		p.consumerWg.Wait()
+	}
+	p.cancel()
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	// 停止所有消费者
-	for _, consumer := range p.consumers {
-		if err := consumer.Stop(ctx); err != nil {
-
+	var lastErr error
+	for _, w := range p.consumers {
+		if err := p.stopPlugin(ctx, w.pluginState, w.consumer); err != nil {
+			lastErr = err
 		}
 	}
 
 	// 停止所有转换器
-	for _, transform := range p.transforms {
-		if err := transform.Stop(ctx); err != nil {
-
+	for _, t := range p.transforms {
+		if err := p.stopPlugin(ctx, t.pluginState, t.transform); err != nil {
+			lastErr = err
 		}
 	}
 
 	// 停止所有过滤器
-	for _, filter := range p.filters {
-		if err := filter.Stop(ctx); err != nil {
-
+	for _, f := range p.filters {
+		if err := p.stopPlugin(ctx, f.pluginState, f.filter); err != nil {
+			lastErr = err
 		}
 	}
 
+	return lastErr
+}
+
+// newPluginState 创建插件运行时状态
+//
+// 指标按插件在所属阶段中的位置区分（如 "filter[1]"），同一管道中的多个同名插件不共用计数；
+// 实例 ID 在热重载后会变化，不用作指标标签。
+func (p *Pipeline) newPluginState(pl plugin.Plugin, position int) *pluginState {
+	s := &pluginState{
+		metrics: newPluginMetrics(p.registry, p.name, fmt.Sprintf("%s[%d]", pl.Type(), position), pl),
+		breaker: newBreaker(p.breakerThreshold, p.breakerCooldown),
+	}
+	if p.plugins != nil {
+		s.id, _ = p.plugins.ID(pl)
+	}
+	return s
+}
+
+// stopPlugin 停止插件：受管理的插件交给 PluginManager，其余直接调用 Stop
+func (p *Pipeline) stopPlugin(ctx context.Context, s *pluginState, pl plugin.Plugin) error {
+	var err error
+	if s.id != "" {
+		err = p.plugins.Stop(ctx, s.id)
+	} else {
+		err = plugin.CallSafely(func() error { return pl.Stop(ctx) })
+	}
+	if err != nil {
+		return p.stopFailed(pl, err)
+	}
 	return nil
 }
+
+// stats 返回插件统计快照（含熔断状态）
+func (s *pluginState) stats() map[string]interface{} {
+	stats := s.metrics.stats()
+	stats["state"] = string(s.breaker.current())
+	return stats
+}
+
+// invoke 在熔断器保护下调用插件处理一条消息
+func (p *Pipeline) invoke(s *pluginState, pl plugin.Plugin, fn func() error) (called bool, err error) {
+	return p.guard(s, pl, func() error {
+		s.metrics.in.Inc()
+		return fn()
+	})
+}
+
+// guard 在熔断器保护下调用插件
+//
+// 熔断中返回 called=false 且不调用 fn。fn 中的 panic 被恢复为错误，
+// 失败会发布到事件总线并计入熔断器。
+func (p *Pipeline) guard(s *pluginState, pl plugin.Plugin, fn func() error) (called bool, err error) {
+	allowed, transition := s.breaker.allow()
+	p.breakerChanged(s, pl, transition)
+	if !allowed {
+		s.metrics.skipped.Inc()
+		return false, nil
+	}
+
+	start := time.Now()
+	err = plugin.CallSafely(fn)
+	s.metrics.latency.Since(start)
+
+	if err != nil {
+		s.metrics.failed.Inc()
+
+		source := p.pluginSource(pl)
+		event.Publish(event.PluginError(source, err))
+		if pe, ok := err.(*plugin.PanicError); ok {
+			event.Publish(event.PluginPanic(source, pe.Stack))
+		}
+
+		p.breakerChanged(s, pl, s.breaker.failure())
+		return true, err
+	}
+
+	p.breakerChanged(s, pl, s.breaker.success())
+	return true, nil
+}
+
+// breakerChanged 发布熔断状态变化事件
+func (p *Pipeline) breakerChanged(s *pluginState, pl plugin.Plugin, state BreakerState) {
+	var message string
+	switch state {
+	case BreakerOpen:
+		s.metrics.trips.Inc()
+		message = fmt.Sprintf("circuit open, retry in %s", p.breakerCooldown)
+	case BreakerHalfOpen:
+		message = "circuit half-open, probing"
+	case BreakerClosed:
+		message = "circuit closed, plugin recovered"
+	default:
+		return
+	}
+
+	// 熔断期间（含半开试探）实例视为降级
+	if s.id != "" {
+		switch state {
+		case BreakerOpen:
+			p.plugins.SetDegraded(s.id, fmt.Errorf("circuit open"))
+		case BreakerClosed:
+			p.plugins.SetDegraded(s.id, nil)
+		}
+	}
+
+	event.Publish(event.PluginState(p.pluginSource(pl), string(state), message))
+}
+
+// pluginSource 返回插件在事件中的来源标识
+func (p *Pipeline) pluginSource(pl plugin.Plugin) string {
+	return fmt.Sprintf("%s/%s", p.name, pl.Name())
+}
+
+// stopFailed 发布插件停止失败事件并返回包装后的错误
+func (p *Pipeline) stopFailed(pl plugin.Plugin, err error) error {
+	err = fmt.Errorf("stop %s: %w", pl.Name(), err)
+	event.Publish(event.PluginError(p.pluginSource(pl), err))
+	return err
+}
+
+// waitGroup 等待 WaitGroup 完成或 ctx 取消
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// OverflowPolicy 队列溢出策略
+type OverflowPolicy string
+
+const (
+	OverflowBlock      OverflowPolicy = "block"       // 阻塞等待队列空位
+	OverflowDropNewest OverflowPolicy = "drop_newest" // 丢弃新到达的消息
+	OverflowDropOldest OverflowPolicy = "drop_oldest" // 丢弃队列中最旧的消息
+)
+
+// 默认队列参数
+const (
+	DefaultQueueSize = 256
+	DefaultOverflow  = OverflowDropOldest
+)
+
+// ErrQueueClosed 队列已关闭
+var ErrQueueClosed = errors.New("queue closed")
+
+// ParseOverflowPolicy 解析溢出策略，空字符串返回默认策略
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch OverflowPolicy(s) {
+	case "":
+		return DefaultOverflow, nil
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+		return OverflowPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid overflow policy: %s", s)
+}
+
+// queue 有界消息队列
+type queue struct {
+	ch     chan *models.Message
+	policy OverflowPolicy
+
+	// done 在关闭时先于 ch 关闭，用于唤醒阻塞中的 push
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+}
+
+// newQueue 创建有界队列
+func newQueue(size int, policy OverflowPolicy) *queue {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	if policy == "" {
+		policy = DefaultOverflow
+	}
+
+	return &queue{
+		ch:     make(chan *models.Message, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+}
+
+// push 按溢出策略入队，返回是否发生了丢弃
+func (q *queue) push(ctx context.Context, msg *models.Message) (bool, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false, ErrQueueClosed
+	}
+
+	switch q.policy {
+	case OverflowDropNewest:
+		select {
+		case q.ch <- msg:
+			return false, nil
+		default:
+			return true, nil
+		}
+
+	case OverflowDropOldest:
+		dropped := false
+		for {
+			select {
+			case q.ch <- msg:
+				return dropped, nil
+			default:
+			}
+
+			// 队列已满，丢弃最旧的一条后重试
+			select {
+			case <-q.ch:
+				dropped = true
+			default:
+			}
+		}
+
+	default:
+		select {
+		case q.ch <- msg:
+			return false, nil
+		case <-q.done:
+			return false, ErrQueueClosed
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// messages 返回出队通道，队列关闭且排空后通道关闭
+func (q *queue) messages() <-chan *models.Message {
+	return q.ch
+}
+
+// close 关闭队列，已入队的消息仍可被消费
+func (q *queue) close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+
+		q.mu.Lock()
+		q.closed = true
+		close(q.ch)
+		q.mu.Unlock()
+	})
+}
+
+// len 返回当前排队的消息数
+func (q *queue) len() int {
+	return len(q.ch)
+}
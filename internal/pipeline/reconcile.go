@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+)
+
+// ErrManagerClosed 管理器已关闭
+var ErrManagerClosed = errors.New("pipeline manager closed")
+
+// Spec 管道的期望状态
+type Spec struct {
+	Name  string                    // 管道名称
+	Input string                    // 上游管道名称，为空表示根管道
+	Hash  string                    // 配置指纹，与运行中管道不同时重建
+	Build func() (*Pipeline, error) // 构建并启动管道
+}
+
+// Reconcile 将运行中的管道调整为 specs 描述的期望状态
+//
+// specs 必须按上游在前的拓扑顺序排列。名称、Input 和 Hash 都未变化的管道保持运行。
+// 其余管道先按 specs 顺序构建新实例（此时旧管道仍在接收消息），挂接完成后一次性替换
+// 分发列表，再按拓扑顺序排空并关闭旧管道（上游排空后再关闭下游），调整过程中不丢消息。
+// 每个旧管道的排空最多等待 DefaultShutdownTimeout。
+// 新旧插件实例会短暂同时运行，Build 需要为新实例分配与旧实例不同的 ID。
+// 单个管道构建失败不影响其他管道：输入未变的同名旧管道继续运行（指纹不变，下次调整时重试），
+// 失败会发布到事件总线并合并为返回的错误。
+func (m *Manager) Reconcile(ctx context.Context, specs []Spec) error {
+	m.reconcileMu.Lock()
+	defer m.reconcileMu.Unlock()
+
+	if m.ctx.Err() != nil {
+		return ErrManagerClosed
+	}
+
+	m.mu.RLock()
+	current := make([]*Pipeline, len(m.pipelines))
+	copy(current, m.pipelines)
+	m.mu.RUnlock()
+
+	byName := make(map[string]*Pipeline, len(current))
+	for _, p := range current {
+		byName[p.Name()] = p
+	}
+
+	var errs []error
+	fail := func(name string, err error) {
+		err = fmt.Errorf("pipeline %s: %w", name, err)
+		event.Publish(event.PluginError(name, err))
+		errs = append(errs, err)
+	}
+
+	// 1. 按 specs 顺序确定新的管道图，未变化的管道保留，其余构建新实例
+	next := make([]*Pipeline, 0, len(specs))
+	built := make(map[string]*Pipeline, len(specs))
+	for _, spec := range specs {
+		if spec.Input != "" && built[spec.Input] == nil {
+			fail(spec.Name, fmt.Errorf("input %s not found", spec.Input))
+			continue
+		}
+
+		p := byName[spec.Name]
+		if p == nil || spec.Input != p.Input() || spec.Hash != p.Hash() {
+			old := p
+			var err error
+			if p, err = spec.Build(); err != nil {
+				fail(spec.Name, err)
+				if old == nil || old.Input() != spec.Input {
+					continue
+				}
+				p = old
+			}
+		}
+
+		next = append(next, p)
+		built[spec.Name] = p
+	}
+
+	inNext := make(map[*Pipeline]bool, len(next))
+	for _, p := range next {
+		inNext[p] = true
+	}
+
+	// 2. 挂接新管道：保留的上游直接把旧下游替换为新下游，避免同一消息发给两者
+	for _, p := range next {
+		if p.Input() == "" {
+			continue
+		}
+		parent := built[p.Input()]
+		if containsPipeline(parent.Downstreams(), p) {
+			continue
+		}
+		if old := byName[p.Name()]; old != nil && old != p {
+			parent.ReplaceDownstream(old, p)
+		} else {
+			parent.AddDownstream(p)
+		}
+	}
+
+	// 3. 一次性替换分发列表，之后的消息只进入新的管道图
+	m.mu.Lock()
+	m.pipelines = next
+	m.mu.Unlock()
+
+	// 4. 旧管道从保留的上游摘除，再按拓扑顺序排空并关闭
+	var stale []*Pipeline
+	for _, p := range current {
+		if !inNext[p] {
+			stale = append(stale, p)
+		}
+	}
+	for _, p := range stale {
+		if parent := byName[p.Input()]; parent != nil && inNext[parent] {
+			parent.RemoveDownstream(p)
+		}
+	}
+	for _, p := range stale {
+		shutdownPipeline(ctx, p)
+	}
+
+	return errors.Join(errs...)
+}
+
+// containsPipeline 判断列表中是否包含指定管道
+func containsPipeline(pipelines []*Pipeline, p *Pipeline) bool {
+	for _, existing := range pipelines {
+		if existing == p {
+			return true
+		}
+	}
+	return false
+}
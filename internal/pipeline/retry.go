@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// 默认消费策略
+const (
+	DefaultConsumerTimeout = 10 * time.Second
+	DefaultMaxAttempts     = 3
+	DefaultRetryBackoff    = 500 * time.Millisecond
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+// ConsumerConfig 消费者调用策略，零值字段使用默认值
+type ConsumerConfig struct {
+	Timeout     time.Duration // 单次 Consume 调用超时
+	MaxAttempts int           // 最多尝试次数（含首次），1 表示不重试
+	Backoff     time.Duration // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoff  time.Duration // 重试等待上限
+}
+
+// withDefaults 返回填充默认值后的策略
+func (c ConsumerConfig) withDefaults() ConsumerConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultConsumerTimeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = c.Backoff
+	}
+	return c
+}
+
+// consume 按消费者策略调用 Consume
+//
+// 每次尝试都经过熔断器并受超时限制；可重试错误（见 plugin.IsRetryable）按指数退避重试，
+// 直到成功、遇到不可重试错误、达到最大次数、熔断或管道关闭。超时不重试。
+// 返回实际尝试次数，首次尝试即被熔断跳过时为 0。
+func (p *Pipeline) consume(w *consumerWorker, msg *models.Message) (int, error) {
+	backoff := w.config.Backoff
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		called, err := p.invoke(w.pluginState, w.consumer, func() error {
+			var err error
+			w.abandoned, err = callWithTimeout(p.ctx, w.config.Timeout, func(ctx context.Context) error {
+				return w.consumer.Consume(ctx, msg)
+			})
+			return err
+		})
+		if !called {
+			return attempt - 1, lastErr
+		}
+
+		lastErr = err
+		if err == nil || attempt >= w.config.MaxAttempts || !plugin.IsRetryable(err) {
+			return attempt, err
+		}
+
+		w.metrics.retries.Inc()
+
+		select {
+		case <-time.After(backoff):
+		case <-p.ctx.Done():
+			return attempt, err
+		}
+
+		backoff *= 2
+		if backoff > w.config.MaxBackoff {
+			backoff = w.config.MaxBackoff
+		}
+	}
+}
+
+// awaitAbandoned 等待上一次超时后被放弃的调用返回，保证同一消费者不会被并发调用
+//
+// 管道关闭时返回 false。
+func (p *Pipeline) awaitAbandoned(w *consumerWorker) bool {
+	if w.abandoned == nil {
+		return true
+	}
+
+	select {
+	case <-w.abandoned:
+		w.abandoned = nil
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// callWithTimeout 在独立协程中调用 fn，超时后立即返回
+//
+// 不响应 ctx 的插件调用会在后台继续运行直至结束，其结果被丢弃；
+// 此时返回的 channel 在调用结束时可读，否则为 nil。fn 中的 panic 被恢复为 *plugin.PanicError。
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) (<-chan error, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- plugin.CallSafely(func() error {
+			return fn(ctx)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		return nil, err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return done, fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+		}
+		return done, ctx.Err()
+	}
+}
+
+// deadLetter 将重试耗尽的消息写入死信接收器
+func (p *Pipeline) deadLetter(w *consumerWorker, msg *models.Message, attempts int, err error) {
+	if p.deadLetters == nil {
+		return
+	}
+
+	entry := deadletter.Entry{
+		Time:     time.Now(),
+		Pipeline: p.name,
+		Plugin:   w.consumer.Name(),
+		Attempts: attempts,
+		Error:    err.Error(),
+		Message:  msg,
+	}
+	if formatted, ok := msg.Data.(*models.FormattedMessage); ok {
+		entry.Content = formatted.Content
+	}
+
+	if err := p.deadLetters.Write(entry); err != nil {
+		event.Publish(event.PluginError(p.pluginSource(w.consumer), err))
+		return
+	}
+	w.metrics.deadLettered.Inc()
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"strings"
+	"time"
+)
+
+// ParseNumber 读取数字配置：YAML 中的整数解析为 int，小数解析为 float64，
+// TUI 中输入的数字同样是两者之一；其他类型返回 false
+func ParseNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// NumberValue 读取数字配置，未配置时返回默认值
+func NumberValue(value interface{}, def float64) float64 {
+	if n, ok := ParseNumber(value); ok {
+		return n
+	}
+	return def
+}
+
+// SecondsValue 读取以秒为单位的数字配置
+func SecondsValue(value interface{}, def time.Duration) time.Duration {
+	return time.Duration(NumberValue(value, def.Seconds()) * float64(time.Second))
+}
+
+// StringValue 读取字符串配置并去掉首尾空白，为空时返回默认值
+func StringValue(value interface{}, def string) string {
+	if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+		return strings.TrimSpace(s)
+	}
+	return def
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// retryableError 可重试错误
+type retryableError struct {
+	err error
+}
+
+// Error 实现 error 接口
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable 将错误标记为可重试（如网络抖动、服务暂不可用），err 为 nil 时返回 nil
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable 返回错误是否被 Retryable 标记为可重试
+//
+// 调用超时不可重试：被放弃的调用可能仍在运行，重试会与之并发。
+func IsRetryable(err error) bool {
+	var r *retryableError
+	return errors.As(err, &r)
+}
+
+// PanicError 插件调用中恢复的 panic
+type PanicError struct {
+	Value interface{}
+	Stack []byte // panic 时的调用栈
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// CallSafely 调用 fn，把 panic 转换为 *PanicError
+func CallSafely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"time"
 
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
@@ -33,6 +34,9 @@ type ConfigField struct {
 	Default interface{}     // 默认值
 	Desc    string          // 字段描述
 	Options []string        // 选项列表（用于 enum 和 array 类型）
+
+	// Validate 保存前校验解析后的值，可为 nil
+	Validate func(value interface{}) error
 }
 
 // Plugin 插件基础接口
@@ -77,6 +81,38 @@ type ConsumerPlugin interface {
 	Consume(ctx context.Context, msg *models.Message) error
 }
 
+// FallibleFilter 可选接口：可能无法给出结果的过滤器（如依赖外部进程）
+//
+// 实现该接口的过滤器由管道调用 TryFilter 代替 Filter，返回的错误计入熔断器，
+// 消息按管道为该过滤器配置的出错策略拦截或放行。
+type FallibleFilter interface {
+	TryFilter(ctx context.Context, msg *models.Message) (bool, error)
+}
+
+// BatchTransformer 可选接口：一条输入产生零条或多条输出的转换器（如聚合、拆分）
+//
+// 实现该接口的转换器由管道调用 TransformBatch 代替 Transform，返回空切片表示消息被暂存或丢弃。
+type BatchTransformer interface {
+	TransformBatch(ctx context.Context, msg *models.Message) ([]*models.Message, error)
+}
+
+// Flusher 可选接口：暂存消息并定时输出的转换器
+//
+// 管道在处理消息的同一协程中按 FlushInterval 调用 Flush，输出的消息继续经过后续转换器，
+// 因此实现无需与 Transform 同步。管道关闭前以 final=true 调用一次，此时应返回全部暂存消息。
+type Flusher interface {
+	// FlushInterval 返回调用 Flush 的间隔
+	FlushInterval() time.Duration
+	// Flush 返回到期的暂存消息
+	Flush(ctx context.Context, final bool) ([]*models.Message, error)
+}
+
+// HealthChecker 可选的健康检查接口，由 PluginManager 定期调用
+type HealthChecker interface {
+	// HealthCheck 检查插件依赖的外部资源，返回 nil 表示健康
+	HealthCheck(ctx context.Context) error
+}
+
 // BasePlugin 插件基础实现（可选继承）
 type BasePlugin struct {
 	name   string
@@ -0,0 +1,431 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+)
+
+// State 插件实例生命周期状态
+type State string
+
+const (
+	StateCreated     State = "created"     // 已创建，尚未初始化
+	StateInitialized State = "initialized" // 已初始化，尚未启动
+	StateRunning     State = "running"     // 运行中且健康
+	StateDegraded    State = "degraded"    // 运行中，但健康检查失败或被上报降级
+	StateStopped     State = "stopped"     // 已停止（停止后的实例从管理器中移除）
+)
+
+// 默认健康检查参数
+const (
+	DefaultHealthInterval = 30 * time.Second
+	DefaultHealthTimeout  = 5 * time.Second
+)
+
+// ErrManagerClosed 插件管理器已关闭
+var ErrManagerClosed = errors.New("plugin manager closed")
+
+// InstanceInfo 插件实例状态快照
+type InstanceInfo struct {
+	ID    string     // 实例 ID，同一插件可以有多个实例
+	Name  string     // 插件名称
+	Type  PluginType // 插件类型
+	State State      // 当前状态
+	Err   error      // 降级或失败原因
+	Since time.Time  // 进入当前状态的时间
+}
+
+// instance 受管理的插件实例
+type instance struct {
+	id     string
+	plugin Plugin
+	state  State
+	err    error
+	since  time.Time
+
+	healthErr   error // 最近一次健康检查的错误
+	reportedErr error // 外部上报的降级原因（如熔断）
+}
+
+// PluginManager 插件管理器，负责插件实例的完整生命周期
+//
+// 实例按 ID 管理，依次经历 created → initialized → running → stopped。
+// 运行中的实例在健康检查失败或被 SetDegraded 上报时进入 degraded，
+// 原因全部消除后回到 running。停止的实例从管理器中移除。
+type PluginManager struct {
+	registry  *Registry
+	instances map[string]*instance
+	byPlugin  map[Plugin]*instance
+	observer  func(InstanceInfo)
+	mu        sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	healthWg  sync.WaitGroup
+
+	// 待通知观察者的状态变化，按发生顺序由 notifyLoop 在锁外投递
+	notifications []InstanceInfo
+	notifyCh      chan struct{}
+}
+
+// NewPluginManager 创建插件管理器
+func NewPluginManager(registry *Registry) *PluginManager {
+	if registry == nil {
+		registry = GlobalRegistry
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &PluginManager{
+		registry:  registry,
+		instances: make(map[string]*instance),
+		byPlugin:  make(map[Plugin]*instance),
+		ctx:       ctx,
+		cancel:    cancel,
+		notifyCh:  make(chan struct{}, 1),
+	}
+	go m.notifyLoop()
+
+	return m
+}
+
+// SetObserver 设置状态变化回调
+//
+// 回调在独立协程中按状态变化的顺序调用，不持有管理器锁，阻塞时只推迟后续通知。
+func (m *PluginManager) SetObserver(fn func(InstanceInfo)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observer = fn
+}
+
+// Load 创建并初始化插件实例
+//
+// 插件类型与 pType 不符或初始化失败时返回错误，实例不会保留在管理器中。
+func (m *PluginManager) Load(id, name string, pType PluginType, config map[string]interface{}) (Plugin, error) {
+	p, err := m.registry.Create(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s %s: %w", pType, name, err)
+	}
+
+	if p.Type() != pType {
+		return nil, fmt.Errorf("plugin %s is a %s, not a %s", name, p.Type(), pType)
+	}
+
+	m.mu.Lock()
+	if m.ctx.Err() != nil {
+		m.mu.Unlock()
+		return nil, ErrManagerClosed
+	}
+	if _, exists := m.instances[id]; exists {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("plugin instance %s already loaded", id)
+	}
+
+	inst := &instance{id: id, plugin: p}
+	m.instances[id] = inst
+	m.byPlugin[p] = inst
+	m.transition(inst, StateCreated, nil)
+	m.mu.Unlock()
+
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	if err := CallSafely(func() error { return p.Init(m.ctx, config) }); err != nil {
+		err = fmt.Errorf("failed to init %s %s: %w", pType, name, err)
+		m.remove(inst, err)
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.transition(inst, StateInitialized, nil)
+	m.mu.Unlock()
+
+	return p, nil
+}
+
+// Start 启动已初始化的插件实例
+//
+// 启动失败的实例直接移除，不再调用其 Stop。
+func (m *PluginManager) Start(id string) error {
+	m.mu.RLock()
+	inst, exists := m.instances[id]
+	var state State
+	if exists {
+		state = inst.state
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("plugin instance %s not loaded", id)
+	}
+	if state != StateInitialized {
+		return fmt.Errorf("plugin instance %s is %s, not %s", id, state, StateInitialized)
+	}
+
+	p := inst.plugin
+	if err := CallSafely(func() error { return p.Start(m.ctx) }); err != nil {
+		err = fmt.Errorf("failed to start %s %s: %w", p.Type(), p.Name(), err)
+		m.remove(inst, err)
+		return err
+	}
+
+	m.mu.Lock()
+	m.transition(inst, StateRunning, nil)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Stop 停止插件实例并将其移除
+func (m *PluginManager) Stop(ctx context.Context, id string) error {
+	m.mu.RLock()
+	inst, exists := m.instances[id]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("plugin instance %s not loaded", id)
+	}
+
+	err := CallSafely(func() error { return inst.plugin.Stop(ctx) })
+	m.remove(inst, err)
+
+	return err
+}
+
+// remove 移除实例并发布 stopped 状态
+func (m *PluginManager) remove(inst *instance, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.instances[inst.id] == inst {
+		delete(m.instances, inst.id)
+		delete(m.byPlugin, inst.plugin)
+	}
+	m.transition(inst, StateStopped, err)
+}
+
+// Get 获取插件实例
+func (m *PluginManager) Get(id string) (Plugin, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inst, exists := m.instances[id]
+	if !exists {
+		return nil, fmt.Errorf("plugin instance %s not loaded", id)
+	}
+
+	return inst.plugin, nil
+}
+
+// ID 返回插件实例的 ID，未受管理时返回 false
+func (m *PluginManager) ID(p Plugin) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inst, exists := m.byPlugin[p]
+	if !exists {
+		return "", false
+	}
+	return inst.id, true
+}
+
+// GetAll 获取所有受管理的插件
+func (m *PluginManager) GetAll() []Plugin {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	plugins := make([]Plugin, 0, len(m.instances))
+	for _, inst := range m.instances {
+		plugins = append(plugins, inst.plugin)
+	}
+	return plugins
+}
+
+// Instances 返回所有实例的状态快照（按 ID 排序）
+func (m *PluginManager) Instances() []InstanceInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]InstanceInfo, 0, len(m.instances))
+	for _, inst := range m.instances {
+		infos = append(infos, inst.info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// SetDegraded 上报运行中实例的降级原因，err 为 nil 表示原因已消除
+func (m *PluginManager) SetDegraded(id string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inst, exists := m.instances[id]
+	if !exists {
+		return
+	}
+
+	inst.reportedErr = err
+	m.refresh(inst)
+}
+
+// CheckHealth 对实现 HealthChecker 的运行中实例执行一次健康检查
+func (m *PluginManager) CheckHealth(ctx context.Context) {
+	m.mu.RLock()
+	targets := make([]*instance, 0, len(m.instances))
+	for _, inst := range m.instances {
+		if inst.state != StateRunning && inst.state != StateDegraded {
+			continue
+		}
+		if _, ok := inst.plugin.(HealthChecker); ok {
+			targets = append(targets, inst)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, inst := range targets {
+		checker := inst.plugin.(HealthChecker)
+
+		checkCtx, cancel := context.WithTimeout(ctx, DefaultHealthTimeout)
+		err := CallSafely(func() error { return checker.HealthCheck(checkCtx) })
+		cancel()
+
+		m.mu.Lock()
+		if m.instances[inst.id] == inst {
+			inst.healthErr = err
+			m.refresh(inst)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// StartHealthChecks 启动定期健康检查，直到 StopAll
+func (m *PluginManager) StartHealthChecks(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+
+	m.healthWg.Add(1)
+	go func() {
+		defer m.healthWg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.CheckHealth(m.ctx)
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// StopAll 停止所有实例并关闭管理器，返回最后一个停止错误
+func (m *PluginManager) StopAll(ctx context.Context) error {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.instances))
+	for id := range m.instances {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+
+	var lastErr error
+	for _, id := range ids {
+		if err := m.Stop(ctx, id); err != nil {
+			lastErr = err
+		}
+	}
+
+	m.cancel()
+	m.healthWg.Wait()
+
+	return lastErr
+}
+
+// refresh 根据降级原因重新计算运行中实例的状态（调用方需持有锁）
+func (m *PluginManager) refresh(inst *instance) {
+	if inst.state != StateRunning && inst.state != StateDegraded {
+		return
+	}
+
+	err := inst.reportedErr
+	if err == nil {
+		err = inst.healthErr
+	}
+
+	if err != nil {
+		m.transition(inst, StateDegraded, err)
+	} else {
+		m.transition(inst, StateRunning, nil)
+	}
+}
+
+// transition 切换实例状态，发布事件并通知观察者（调用方需持有锁）
+func (m *PluginManager) transition(inst *instance, state State, err error) {
+	if inst.state == state && (inst.err == nil) == (err == nil) {
+		inst.err = err
+		return
+	}
+
+	inst.state = state
+	inst.err = err
+	inst.since = time.Now()
+
+	event.Publish(event.PluginHealth(inst.id, string(state), err))
+	if m.observer != nil {
+		m.notifications = append(m.notifications, inst.info())
+		select {
+		case m.notifyCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyLoop 把状态变化依次交给观察者，管理器关闭并投递完剩余通知后退出
+func (m *PluginManager) notifyLoop() {
+	for {
+		select {
+		case <-m.notifyCh:
+		case <-m.ctx.Done():
+		}
+
+		m.mu.Lock()
+		pending := m.notifications
+		m.notifications = nil
+		observer := m.observer
+		m.mu.Unlock()
+
+		for _, info := range pending {
+			if observer != nil {
+				observer(info)
+			}
+		}
+
+		if m.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// info 返回实例状态快照（调用方需持有锁）
+func (inst *instance) info() InstanceInfo {
+	return InstanceInfo{
+		ID:    inst.id,
+		Name:  inst.plugin.Name(),
+		Type:  inst.plugin.Type(),
+		State: inst.state,
+		Err:   inst.err,
+		Since: inst.since,
+	}
+}
@@ -1,8 +1,8 @@
 package plugin
 
 import (
-	"context"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -97,6 +97,29 @@ func (r *Registry) GetAllConsumerPluginInfo() []PluginInfo {
 	return consumerInfos
 }
 
+// GetPluginInfoByType 获取指定类型插件的信息（按名称排序）
+func (r *Registry) GetPluginInfoByType(pType PluginType) []PluginInfo {
+	allInfos := r.GetAllPluginInfo()
+	infos := make([]PluginInfo, 0)
+	for _, info := range allInfos {
+		if info.Type == pType {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// GetPluginInfo 获取单个插件的信息
+func (r *Registry) GetPluginInfo(name string) (PluginInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, exists := r.infos[name]
+	return info, exists
+}
+
 // Has 检查插件是否已注册
 func (r *Registry) Has(name string) bool {
 	r.mu.RLock()
@@ -117,124 +140,3 @@ func Register(name string, factory PluginFactory, info PluginInfo) error {
 func Create(name string) (Plugin, error) {
 	return GlobalRegistry.Create(name)
 }
-
-// PluginManager 插件管理器
-type PluginManager struct {
-	registry *Registry
-	plugins  map[string]Plugin
-	mu       sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
-}
-
-// NewPluginManager 创建插件管理器
-func NewPluginManager(registry *Registry) *PluginManager {
-	if registry == nil {
-		registry = GlobalRegistry
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-
-	return &PluginManager{
-		registry: registry,
-		plugins:  make(map[string]Plugin),
-		ctx:      ctx,
-		cancel:   cancel,
-	}
-}
-
-// Load 加载插件
-func (m *PluginManager) Load(name string, config map[string]interface{}) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// 检查是否已加载
-	if _, exists := m.plugins[name]; exists {
-		return fmt.Errorf("plugin %s already loaded", name)
-	}
-
-	// 创建插件实例
-	plugin, err := m.registry.Create(name)
-	if err != nil {
-		return fmt.Errorf("create plugin %s: %w", name, err)
-	}
-
-	// 初始化插件
-	if err := plugin.Init(m.ctx, config); err != nil {
-		return fmt.Errorf("init plugin %s: %w", name, err)
-	}
-
-	m.plugins[name] = plugin
-	return nil
-}
-
-// Start 启动插件
-func (m *PluginManager) Start(name string) error {
-	m.mu.RLock()
-	plugin, exists := m.plugins[name]
-	m.mu.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("plugin %s not loaded", name)
-	}
-
-	return plugin.Start(m.ctx)
-}
-
-// Stop 停止插件
-func (m *PluginManager) Stop(name string) error {
-	m.mu.RLock()
-	plugin, exists := m.plugins[name]
-	m.mu.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("plugin %s not loaded", name)
-	}
-
-	return plugin.Stop(m.ctx)
-}
-
-// Get 获取插件实例
-func (m *PluginManager) Get(name string) (Plugin, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	plugin, exists := m.plugins[name]
-	if !exists {
-		return nil, fmt.Errorf("plugin %s not loaded", name)
-	}
-
-	return plugin, nil
-}
-
-// GetAll 获取所有已加载的插件
-func (m *PluginManager) GetAll() []Plugin {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	plugins := make([]Plugin, 0, len(m.plugins))
-	for _, plugin := range m.plugins {
-		plugins = append(plugins, plugin)
-	}
-	return plugins
-}
-
-// StopAll 停止所有插件
-func (m *PluginManager) StopAll() error {
-	m.mu.RLock()
-	plugins := make([]Plugin, 0, len(m.plugins))
-	for _, plugin := range m.plugins {
-		plugins = append(plugins, plugin)
-	}
-	m.mu.RUnlock()
-
-	var lastErr error
-	for _, plugin := range plugins {
-		if err := plugin.Stop(m.ctx); err != nil {
-			lastErr = err
-		}
-	}
-
-	m.cancel()
-	return lastErr
-}
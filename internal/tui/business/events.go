@@ -0,0 +1,70 @@
+package business
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/tui"
+)
+
+// statusThrottle 转发到状态栏的最小间隔，避免消息洪峰时刷屏
+const statusThrottle = 200 * time.Millisecond
+
+// startEventForwarding 订阅事件总线，写入日志文件并把警告以上的事件转发到 TUI 状态栏
+func (m *Manager) startEventForwarding() {
+	events, unsubscribe := event.Subscribe(256)
+	m.stopEvents = unsubscribe
+
+	minLevel := event.ParseLevel(m.config.Client.LogLevel)
+	if m.config.Client.Debug {
+		minLevel = event.LevelDebug
+	}
+
+	logger, logFile := openEventLog()
+
+	go func() {
+		if logFile != nil {
+			defer logFile.Close()
+		}
+
+		var lastStatus time.Time
+		for e := range events {
+			if logger != nil && e.Level >= minLevel {
+				logger.Printf("[%s] %s %s", e.Level, e.Type, e)
+			}
+
+			// 熔断状态变化不节流，保证 TUI 显示的状态准确
+			if e.Type == event.TypePluginState {
+				m.program.Send(tuimsg.PluginStateMsg{Source: e.Source, State: e.State})
+			}
+
+			if e.Level >= event.LevelWarn && time.Since(lastStatus) >= statusThrottle {
+				lastStatus = time.Now()
+				m.program.Send(tuimsg.EventMsg{Event: e})
+			}
+		}
+	}()
+}
+
+// openEventLog 打开日志文件，失败时返回 nil（仅在 TUI 中显示事件）
+func openEventLog() (*log.Logger, io.Closer) {
+	if err := tui.EnsureConfigDir(); err != nil {
+		return nil, nil
+	}
+
+	logPath, err := tui.GetLogPath()
+	if err != nil {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, nil
+	}
+
+	return log.New(file, "", log.LstdFlags), file
+}
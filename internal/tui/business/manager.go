@@ -2,11 +2,19 @@ package business
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
 	"github.com/xifan2333/dmnotifier/internal/client"
 	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/metrics"
 	"github.com/xifan2333/dmnotifier/internal/pipeline"
 	"github.com/xifan2333/dmnotifier/internal/tui"
 	"github.com/xifan2333/dmnotifier/internal/tui/components"
@@ -16,21 +24,87 @@ import (
 
 // Manager 业务逻辑管理器
 type Manager struct {
-	program         *tea.Program
-	apiClient       *api.Client
+	program   *tea.Program
+	apiClient *api.Client
+
+	// 当前连接，connMu 串行化连接、断开与热重载
 	wsClient        *client.WSClient
 	pipelineManager *pipeline.Manager
-	config          *tui.AppConfig
+	connMu          sync.Mutex
+
+	// 配置，TUI 协程写入、后台协程读取，读取时通过 configSnapshot 复制
+	config   *tui.AppConfig
+	configMu sync.Mutex
+
+	// 取消事件总线订阅
+	stopEvents func()
+
+	// Prometheus 指标服务
+	metricsServer *metrics.Server
+
+	// 死信接收器，所有 pipeline 共用，热重载时不重新创建
+	deadLetters deadletter.Sink
+
+	// 插件配置变更后的 pipeline 热重载（防抖）
+	reloadTimer *time.Timer
+	reloadMutex sync.Mutex
 }
 
+// reloadDelay 插件配置变更到热重载之间的防抖延迟
+const reloadDelay = 500 * time.Millisecond
+
 // NewManager 创建业务逻辑管理器
 func NewManager(program *tea.Program, config *tui.AppConfig) *Manager {
 	apiClient := api.NewClient(config.Server.APIAddress, config.Server.APIToken)
-	return &Manager{
+	m := &Manager{
 		program:   program,
 		apiClient: apiClient,
 		config:    config,
 	}
+
+	m.startEventForwarding()
+	m.startMetricsServer()
+	m.loadBlockList()
+	m.openDeadLetters()
+
+	return m
+}
+
+// loadBlockList 加载用户屏蔽列表，失败时使用空列表
+func (m *Manager) loadBlockList() {
+	path, err := tui.GetBlockListPath()
+	if err == nil {
+		err = blocklist.Default.Load(path)
+	}
+	if err != nil {
+		event.Publish(event.PluginError("blocklist", err))
+	}
+}
+
+// openDeadLetters 创建死信接收器，路径无法确定时不写入死信
+func (m *Manager) openDeadLetters() {
+	path, err := tui.GetDeadLetterPath()
+	if err != nil {
+		event.Publish(event.PluginError("deadletter", err))
+		return
+	}
+	m.deadLetters = deadletter.NewFileSink(path)
+}
+
+// startMetricsServer 按配置启动指标服务
+func (m *Manager) startMetricsServer() {
+	if !m.config.Metrics.Enabled || m.config.Metrics.Address == "" {
+		return
+	}
+
+	server := metrics.NewServer(m.config.Metrics.Address, nil)
+	if err := server.Start(); err != nil {
+		event.Publish(event.PluginError("metrics", err))
+		return
+	}
+
+	m.metricsServer = server
+	event.Publish(event.PluginStatus("metrics", fmt.Sprintf("serving http://%s/metrics", server.Addr())))
 }
 
 // GetAPIClient 获取 API 客户端
@@ -40,15 +114,70 @@ func (m *Manager) GetAPIClient() *api.Client {
 
 // UpdateServerConfig 更新服务器配置
 func (m *Manager) UpdateServerConfig(apiAddress, apiToken, wsAddress string) {
+	m.configMu.Lock()
 	m.config.Server.APIAddress = apiAddress
 	m.config.Server.APIToken = apiToken
 	m.config.Server.WSAddress = wsAddress
+	m.configMu.Unlock()
 	m.apiClient = api.NewClient(apiAddress, apiToken)
 }
 
-// UpdatePluginsConfig 更新插件配置
+// UpdatePluginsConfig 更新插件配置，已连接时在防抖后热重载 pipeline
 func (m *Manager) UpdatePluginsConfig(plugins []tuimsg.PluginConfig) {
+	m.configMu.Lock()
 	m.config.Pipeline.Plugins = plugins
+	m.configMu.Unlock()
+	m.scheduleReload()
+}
+
+// configSnapshot 返回当前配置的副本，供后台协程构建 pipeline 使用
+func (m *Manager) configSnapshot() *tui.AppConfig {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+
+	config := *m.config
+	config.Pipeline.Plugins = append([]tuimsg.PluginConfig(nil), m.config.Pipeline.Plugins...)
+	return &config
+}
+
+// scheduleReload 延迟热重载，连续修改只触发一次
+func (m *Manager) scheduleReload() {
+	m.reloadMutex.Lock()
+	defer m.reloadMutex.Unlock()
+
+	if m.reloadTimer != nil {
+		m.reloadTimer.Stop()
+	}
+
+	m.reloadTimer = time.AfterFunc(reloadDelay, m.reloadPipelines)
+}
+
+// reloadPipelines 按当前配置调整运行中的 pipeline，不影响 WebSocket 连接
+//
+// 只有配置发生变化的 pipeline 会被关闭和重建，单个 pipeline 的失败已发布到事件总线。
+func (m *Manager) reloadPipelines() {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	pipelineManager := m.pipelineManager
+	if pipelineManager == nil {
+		return
+	}
+
+	specs, err := pipelineSpecs(m.configSnapshot(), m.program, pipelineManager.Plugins(), m.deadLetters)
+	if err != nil {
+		m.program.Send(tuimsg.ErrorMsg{Err: fmt.Errorf("failed to reload pipelines: %w", err)})
+		return
+	}
+
+	if err := pipelineManager.Reconcile(context.Background(), specs); err != nil {
+		if !errors.Is(err, pipeline.ErrManagerClosed) {
+			m.program.Send(tuimsg.StatusMsg{Message: "Pipelines reloaded with errors"})
+		}
+		return
+	}
+
+	m.program.Send(tuimsg.StatusMsg{Message: "Pipelines reloaded"})
 }
 
 // GetConfig 获取配置
@@ -67,6 +196,63 @@ func (m *Manager) FetchServices() tea.Cmd {
 	}
 }
 
+// deadLettersLimit 死信弹窗最多加载的记录数
+const deadLettersLimit = 200
+
+// LoadDeadLetters 读取最近的死信记录
+func (m *Manager) LoadDeadLetters() tea.Cmd {
+	return func() tea.Msg {
+		path, err := tui.GetDeadLetterPath()
+		if err != nil {
+			return tuimsg.DeadLettersLoadedMsg{Err: err}
+		}
+
+		entries, err := deadletter.ReadRecent(path, deadLettersLimit)
+		return tuimsg.DeadLettersLoadedMsg{Entries: entries, Err: err}
+	}
+}
+
+// BlockUser 屏蔽用户，立即对 user_block_filter 生效
+//
+// 有启用的消费者没有经过 user_block_filter 时在状态栏提示，屏蔽对它们不生效。
+func (m *Manager) BlockUser(user blocklist.User) tea.Cmd {
+	return func() tea.Msg {
+		added, err := blocklist.Default.Add(user)
+		if err != nil {
+			return tuimsg.ErrorMsg{Err: fmt.Errorf("failed to save block list: %w", err)}
+		}
+
+		message := fmt.Sprintf("Blocked %s", user)
+		if !added {
+			message = fmt.Sprintf("%s is already blocked", user)
+		}
+		if missing := consumersWithoutFilter(m.configSnapshot(), "user_block_filter"); len(missing) > 0 {
+			message += fmt.Sprintf(" (no user_block_filter for: %s)", strings.Join(missing, ", "))
+		}
+		return tuimsg.StatusMsg{Message: message}
+	}
+}
+
+// UnblockUser 取消屏蔽用户，返回更新后的屏蔽列表
+func (m *Manager) UnblockUser(user blocklist.User) tea.Cmd {
+	return func() tea.Msg {
+		removed, err := blocklist.Default.Remove(user)
+		if err != nil {
+			m.program.Send(tuimsg.ErrorMsg{Err: fmt.Errorf("failed to save block list: %w", err)})
+		} else if removed {
+			m.program.Send(tuimsg.StatusMsg{Message: fmt.Sprintf("Unblocked %s", user)})
+		}
+		return tuimsg.BlockListLoadedMsg{Entries: blocklist.Default.List()}
+	}
+}
+
+// LoadBlockList 读取屏蔽列表
+func (m *Manager) LoadBlockList() tea.Cmd {
+	return func() tea.Msg {
+		return tuimsg.BlockListLoadedMsg{Entries: blocklist.Default.List()}
+	}
+}
+
 // StopService 停止服务
 func (m *Manager) StopService(platform, rid string) tea.Cmd {
 	return func() tea.Msg {
@@ -104,20 +290,25 @@ func (m *Manager) AddService(platform, rid, cookie string) tea.Cmd {
 func (m *Manager) ConnectToService(service *api.Service) tea.Cmd {
 	// 在独立 goroutine 中执行所有初始化，避免阻塞 TUI
 	go func() {
+		m.connMu.Lock()
+		defer m.connMu.Unlock()
+
 		// 如果已有连接，先断开
 		if m.wsClient != nil {
-			m.disconnectSync()
+			m.disconnectLocked()
 		}
 
+		config := m.configSnapshot()
+
 		// 异步构建 pipeline 管理器，传入 program 实例
-		pipelineManager, err := BuildPipelines(m.config, m.program)
+		pipelineManager, err := BuildPipelines(config, m.program, m.deadLetters)
 		if err != nil {
-			pipelineManager = pipeline.NewManager()
+			m.program.Send(tuimsg.ErrorMsg{Err: fmt.Errorf("failed to build pipelines: %w", err)})
 		}
 		m.pipelineManager = pipelineManager
 
 		// 构建 WebSocket URL
-		wsURL := fmt.Sprintf("%s/%s/%s", m.config.Server.WSAddress, service.Platform, service.RID)
+		wsURL := fmt.Sprintf("%s/%s/%s", config.Server.WSAddress, service.Platform, service.RID)
 
 		// 创建 WebSocket 客户端
 		m.wsClient = client.NewWSClient(client.WSClientConfig{
@@ -125,12 +316,21 @@ func (m *Manager) ConnectToService(service *api.Service) tea.Cmd {
 			EnableReconnect: true,
 			Handler: func(msg *models.Message) error {
 				// 分发消息到 pipeline（包括 TUI 插件）
-				if m.pipelineManager != nil {
-					m.pipelineManager.Dispatch(context.Background(), msg)
+				if pipelineManager != nil {
+					pipelineManager.Dispatch(context.Background(), msg)
 				}
 
 				return nil
 			},
+			OnStateChange: func(change client.StateChange) {
+				m.program.Send(tuimsg.ConnectionStateMsg{
+					State:      string(change.State),
+					Attempt:    change.Attempt,
+					Delay:      change.Delay,
+					Reconnects: change.Stats.Reconnects,
+					Err:        change.Err,
+				})
+			},
 		})
 
 		// 启动 WebSocket 连接
@@ -158,7 +358,9 @@ func (m *Manager) ConnectToService(service *api.Service) tea.Cmd {
 func (m *Manager) DisconnectService() {
 	// 在独立 goroutine 中执行断开操作和发送消息，避免阻塞 TUI
 	go func() {
-		m.disconnectSync()
+		m.connMu.Lock()
+		m.disconnectLocked()
+		m.connMu.Unlock()
 
 		// 断开完成后通知 TUI
 		m.program.Send(tuimsg.ServiceDisconnectedMsg{})
@@ -166,8 +368,8 @@ func (m *Manager) DisconnectService() {
 	}()
 }
 
-// disconnectSync 同步断开连接（内部使用）
-func (m *Manager) disconnectSync() {
+// disconnectLocked 同步断开连接（调用方需持有 connMu）
+func (m *Manager) disconnectLocked() {
 	if m.wsClient != nil {
 		m.wsClient.Close()
 		m.wsClient = nil
@@ -182,4 +384,14 @@ func (m *Manager) disconnectSync() {
 // Cleanup 清理资源
 func (m *Manager) Cleanup() {
 	m.DisconnectService()
+
+	if m.metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		m.metricsServer.Stop(ctx)
+		cancel()
+	}
+
+	if m.stopEvents != nil {
+		m.stopEvents()
+	}
 }
@@ -2,111 +2,417 @@ package business
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
 	"github.com/xifan2333/dmnotifier/internal/pipeline"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/internal/tui"
 )
 
 // BuildPipelines 根据配置构建所有 pipeline
-func BuildPipelines(config *tui.AppConfig, program *tea.Program) (*pipeline.Manager, error) {
-	ctx := context.Background()
+//
+// 单个 pipeline 构建失败会发布到事件总线，不影响其他 pipeline。
+// deadLetters 由调用方持有，所有 pipeline（包括热重载前后的）共用同一个接收器。
+func BuildPipelines(config *tui.AppConfig, program *tea.Program, deadLetters deadletter.Sink) (*pipeline.Manager, error) {
 	manager := pipeline.NewManager()
 
+	// 消费者实例状态变化转发到 TUI
+	manager.Plugins().SetObserver(func(info plugin.InstanceInfo) {
+		if info.Type != plugin.TypeConsumer {
+			return
+		}
+
+		msg := tuimsg.PluginHealthMsg{ID: info.ID, Name: info.Name, State: string(info.State)}
+		if info.Err != nil {
+			msg.Err = info.Err.Error()
+		}
+		program.Send(msg)
+	})
+
+	specs, err := pipelineSpecs(config, program, manager.Plugins(), deadLetters)
+	if err != nil {
+		return manager, err
+	}
+
+	manager.Reconcile(context.Background(), specs)
+
+	return manager, nil
+}
+
+// pipelineSpecs 根据配置生成所有 pipeline 的期望状态
+//
+// 被启用插件引用的共享阶段按拓扑顺序排在前面，每个启用的消费者插件各对应一个
+// pipeline，配置了 Input 的 pipeline 挂接到对应共享阶段之后。插件实例由 plugins 创建和管理，
+// 启用死信时失败消息写入 deadLetters。
+func pipelineSpecs(config *tui.AppConfig, program *tea.Program, plugins *plugin.PluginManager, deadLetters deadletter.Sink) ([]pipeline.Spec, error) {
+	ctx := context.Background()
+
+	overflow, err := pipeline.ParseOverflowPolicy(config.Pipeline.OverflowPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	baseConfig := pipeline.PipelineConfig{
+		Enabled:          true,
+		QueueSize:        config.Pipeline.QueueSize,
+		Overflow:         overflow,
+		BreakerThreshold: config.Pipeline.BreakerThreshold,
+		BreakerCooldown:  config.Pipeline.BreakerCooldown,
+	}
+
+	// 死信接收器和插件管理器是运行时对象，不参与配置指纹，死信只记录开关
+	hashBase := baseConfig
+	baseConfig.Plugins = plugins
+	if config.Pipeline.DeadLetter {
+		baseConfig.DeadLetter = deadLetters
+	}
+
+	order, err := orderStages(config.Pipeline.Stages, config.Pipeline.Plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]pipeline.Spec, 0, len(order)+len(config.Pipeline.Plugins))
+
+	// 共享阶段
+	for _, stageCfg := range order {
+		pipelineConfig := baseConfig
+		pipelineConfig.Name = stagePipelineName(stageCfg.ID)
+		if stageCfg.Input != "" {
+			pipelineConfig.Input = stagePipelineName(stageCfg.Input)
+		}
+		pipelineConfig.Hash = configHash(hashBase, config.Pipeline.DeadLetter, stageCfg)
+
+		specs = append(specs, pipeline.Spec{
+			Name:  pipelineConfig.Name,
+			Input: pipelineConfig.Input,
+			Hash:  pipelineConfig.Hash,
+			Build: func() (*pipeline.Pipeline, error) {
+				return buildSharedStage(ctx, pipelineConfig, stageCfg)
+			},
+		})
+	}
+
 	// 为每个启用的消费者插件创建一个 pipeline
 	for _, pluginCfg := range config.Pipeline.Plugins {
 		if !pluginCfg.Enabled {
 			continue
 		}
 
-		p, err := buildPipelineForConsumer(ctx, pluginCfg, program)
-		if err != nil {
+		pipelineConfig := baseConfig
+		pipelineConfig.Name = fmt.Sprintf("%s_pipeline", pluginCfg.Name)
+		if pluginCfg.Input != "" {
+			pipelineConfig.Input = stagePipelineName(pluginCfg.Input)
+		}
+		pipelineConfig.Hash = configHash(hashBase, config.Pipeline.DeadLetter, pluginCfg)
+
+		specs = append(specs, pipeline.Spec{
+			Name:  pipelineConfig.Name,
+			Input: pipelineConfig.Input,
+			Hash:  pipelineConfig.Hash,
+			Build: func() (*pipeline.Pipeline, error) {
+				return buildPipelineForConsumer(ctx, pipelineConfig, pluginCfg, program)
+			},
+		})
+	}
+
+	return specs, nil
+}
+
+// configHash 计算配置指纹（fmt 按键排序输出 map，结果稳定）
+func configHash(values ...interface{}) string {
+	h := sha256.New()
+	for _, v := range values {
+		fmt.Fprintf(h, "%#v\n", v)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// orderStages 返回启用插件（直接或间接）引用的共享阶段，按上游在前的拓扑顺序排列
+//
+// 阶段 ID 重复或为空、引用不存在的阶段以及循环引用都会返回错误。
+func orderStages(stages []tuimsg.SharedStageConfig, plugins []tuimsg.PluginConfig) ([]tuimsg.SharedStageConfig, error) {
+	byID := make(map[string]tuimsg.SharedStageConfig, len(stages))
+	for _, stageCfg := range stages {
+		if stageCfg.ID == "" {
+			return nil, fmt.Errorf("pipeline stage without id")
+		}
+		if _, exists := byID[stageCfg.ID]; exists {
+			return nil, fmt.Errorf("duplicate pipeline stage %s", stageCfg.ID)
+		}
+		byID[stageCfg.ID] = stageCfg
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(stages))
+	order := make([]tuimsg.SharedStageConfig, 0, len(stages))
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("pipeline stage %s is part of a cycle", id)
+		case done:
+			return nil
+		}
+
+		stageCfg, exists := byID[id]
+		if !exists {
+			return fmt.Errorf("pipeline stage %s not found", id)
+		}
+
+		state[id] = visiting
+		if stageCfg.Input != "" {
+			if err := visit(stageCfg.Input); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+
+		order = append(order, stageCfg)
+		return nil
+	}
+
+	for _, pluginCfg := range plugins {
+		if !pluginCfg.Enabled || pluginCfg.Input == "" {
 			continue
 		}
+		if err := visit(pluginCfg.Input); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginCfg.Name, err)
+		}
+	}
+
+	return order, nil
+}
 
-		manager.AddPipeline(p)
+// consumersWithoutFilter 返回消息不会经过指定过滤器的已启用消费者插件
+//
+// 依次检查插件自己的过滤器链以及通过 Input 串联的各个共享阶段。
+func consumersWithoutFilter(config *tui.AppConfig, filter string) []string {
+	stages := make(map[string]tuimsg.SharedStageConfig, len(config.Pipeline.Stages))
+	for _, stageCfg := range config.Pipeline.Stages {
+		stages[stageCfg.ID] = stageCfg
 	}
 
-	return manager, nil
+	var missing []string
+	for _, pluginCfg := range config.Pipeline.Plugins {
+		if !pluginCfg.Enabled {
+			continue
+		}
+
+		found := hasStage(pluginCfg.Filters, filter)
+		seen := make(map[string]bool)
+		for input := pluginCfg.Input; !found && input != "" && !seen[input]; {
+			seen[input] = true
+			stageCfg := stages[input]
+			found = hasStage(stageCfg.Filters, filter)
+			input = stageCfg.Input
+		}
+
+		if !found {
+			missing = append(missing, pluginCfg.Name)
+		}
+	}
+	return missing
+}
+
+// hasStage 检查阶段链中是否包含指定插件
+func hasStage(chain []tuimsg.StageConfig, name string) bool {
+	for _, stage := range chain {
+		if stage.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stagePipelineName 返回共享阶段对应的 pipeline 名称
+func stagePipelineName(id string) string {
+	return fmt.Sprintf("%s_stage", id)
+}
+
+// defaultTransforms 未配置转换器链时使用的默认链
+var defaultTransforms = []tuimsg.StageConfig{{Name: "format_transform"}}
+
+// buildSharedStage 构建共享阶段 pipeline（只有过滤器和转换器，结果转发给下游）
+func buildSharedStage(ctx context.Context, pipelineConfig pipeline.PipelineConfig, stageCfg tuimsg.SharedStageConfig) (_ *pipeline.Pipeline, err error) {
+	p := pipeline.NewPipeline(pipelineConfig)
+
+	defer func() {
+		if err != nil {
+			p.Shutdown(ctx)
+		}
+	}()
+
+	ids := newInstanceIDs(pipelineConfig.Plugins, pipelineConfig.Name)
+	if err := addStages(p, pipelineConfig.Plugins, ids, stageCfg.Filters, stageCfg.Transforms); err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 // buildPipelineForConsumer 为单个消费者插件构建 pipeline
-func buildPipelineForConsumer(ctx context.Context, pluginCfg tuimsg.PluginConfig, program *tea.Program) (*pipeline.Pipeline, error) {
+//
+// 阶段顺序：message_type_filter（由 MessageTypes 生成）→ Filters → Transforms → 消费者。
+// 配置了 Input 时消息来自上游共享阶段，未配置 Transforms 也不再添加默认转换器。
+func buildPipelineForConsumer(ctx context.Context, pipelineConfig pipeline.PipelineConfig, pluginCfg tuimsg.PluginConfig, program *tea.Program) (_ *pipeline.Pipeline, err error) {
 	// 创建 pipeline
-	p := pipeline.NewPipeline(pipeline.PipelineConfig{
-		Name:    fmt.Sprintf("%s_pipeline", pluginCfg.Name),
-		Enabled: true,
-	})
+	p := pipeline.NewPipeline(pipelineConfig)
 
-	// 1. 添加消息类型过滤器
-	if len(pluginCfg.MessageTypes) > 0 {
-		typeFilter, err := plugin.Create("message_type_filter")
+	// 构建失败时关闭 pipeline，停止其协程和已添加的插件
+	defer func() {
 		if err != nil {
-			return nil, fmt.Errorf("failed to create message_type_filter: %w", err)
+			p.Shutdown(ctx)
 		}
+	}()
+
+	ids := newInstanceIDs(pipelineConfig.Plugins, pipelineConfig.Name)
+	plugins := pipelineConfig.Plugins
 
+	// 1. 添加消息类型过滤器
+	if len(pluginCfg.MessageTypes) > 0 {
 		// 转换 MessageTypes 为 interface{} 切片
 		types := make([]interface{}, len(pluginCfg.MessageTypes))
 		for i, t := range pluginCfg.MessageTypes {
 			types[i] = t
 		}
 
-		if err := typeFilter.Init(ctx, map[string]interface{}{
+		typeFilter, err := createStage(plugins, ids, "message_type_filter", plugin.TypeFilter, map[string]interface{}{
 			"types": types,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to init message_type_filter: %w", err)
-		}
-
-		if err := typeFilter.Start(ctx); err != nil {
-			return nil, fmt.Errorf("failed to start message_type_filter: %w", err)
+		})
+		if err != nil {
+			return nil, err
 		}
 
 		p.AddFilter(typeFilter.(plugin.FilterPlugin))
 	}
 
-	// 2. 添加格式化转换器
-	formatTransform, err := plugin.Create("format_transform")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create format_transform: %w", err)
+	// 2. 添加配置的过滤器链和转换器链
+	transforms := pluginCfg.Transforms
+	if transforms == nil && pluginCfg.Input == "" {
+		transforms = defaultTransforms
 	}
 
-	if err := formatTransform.Init(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to init format_transform: %w", err)
+	if err := addStages(p, plugins, ids, pluginCfg.Filters, transforms); err != nil {
+		return nil, err
 	}
 
-	if err := formatTransform.Start(ctx); err != nil {
-		return nil, fmt.Errorf("failed to start format_transform: %w", err)
+	// 3. 添加消费者插件
+	// 为 TUI 插件传入 program 实例（复制配置，避免写回配置文件）
+	config := make(map[string]interface{}, len(pluginCfg.Config)+1)
+	for k, v := range pluginCfg.Config {
+		config[k] = v
+	}
+	if pluginCfg.Name == "tui" {
+		config["program"] = program
 	}
 
-	p.AddTransform(formatTransform.(plugin.TransformPlugin))
-
-	// 3. 添加消费者插件
-	consumer, err := plugin.Create(pluginCfg.Name)
+	consumer, err := createStage(plugins, ids, pluginCfg.Name, plugin.TypeConsumer, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create consumer %s: %w", pluginCfg.Name, err)
+		return nil, err
 	}
 
-	// 为 TUI 插件传入 program 实例
-	config := pluginCfg.Config
-	if pluginCfg.Name == "tui" {
-		if config == nil {
-			config = make(map[string]interface{})
+	p.AddConsumerWithConfig(consumer.(plugin.ConsumerPlugin), pipeline.ConsumerConfig{
+		Timeout:     pluginCfg.Timeout,
+		MaxAttempts: pluginCfg.Retry.MaxAttempts,
+		Backoff:     pluginCfg.Retry.Backoff,
+		MaxBackoff:  pluginCfg.Retry.MaxBackoff,
+	})
+
+	return p, nil
+}
+
+// addStages 按顺序创建并添加过滤器链和转换器链
+func addStages(p *pipeline.Pipeline, plugins *plugin.PluginManager, ids *instanceIDs, filters, transforms []tuimsg.StageConfig) error {
+	for _, stage := range filters {
+		onError, err := pipeline.ParseFilterErrorPolicy(stage.OnError)
+		if err != nil {
+			return fmt.Errorf("filter %s: %w", stage.Name, err)
 		}
-		config["program"] = program
+
+		filter, err := createStage(plugins, ids, stage.Name, plugin.TypeFilter, stage.Config)
+		if err != nil {
+			return err
+		}
+
+		p.AddFilterWithConfig(filter.(plugin.FilterPlugin), pipeline.FilterConfig{OnError: onError})
 	}
 
-	if err := consumer.Init(ctx, config); err != nil {
-		return nil, fmt.Errorf("failed to init consumer %s: %w", pluginCfg.Name, err)
+	for _, stage := range transforms {
+		transform, err := createStage(plugins, ids, stage.Name, plugin.TypeTransform, stage.Config)
+		if err != nil {
+			return err
+		}
+
+		p.AddTransform(transform.(plugin.TransformPlugin))
 	}
 
-	if err := consumer.Start(ctx); err != nil {
-		return nil, fmt.Errorf("failed to start consumer %s: %w", pluginCfg.Name, err)
+	return nil
+}
+
+// createStage 通过插件管理器创建、初始化并启动指定类型的插件
+func createStage(plugins *plugin.PluginManager, ids *instanceIDs, name string, pType plugin.PluginType, config map[string]interface{}) (plugin.Plugin, error) {
+	id := ids.next(name)
+
+	instance, err := plugins.Load(id, name, pType, config)
+	if err != nil {
+		return nil, err
 	}
 
-	p.AddConsumer(consumer.(plugin.ConsumerPlugin))
+	if err := plugins.Start(id); err != nil {
+		return nil, err
+	}
 
-	return p, nil
+	return instance, nil
+}
+
+// instanceIDs 为同一 pipeline 内的插件实例分配 ID
+//
+// ID 形如 "pipeline/插件"，与事件来源一致；同名插件重复出现时追加序号。
+// 热重载时新 pipeline 在旧 pipeline 停止前构建，旧实例仍占用原来的 ID，
+// 此时换用下一代前缀（如 "tts_pipeline@2/tts"）。
+type instanceIDs struct {
+	prefix string
+	seen   map[string]int
+}
+
+// newInstanceIDs 创建实例 ID 分配器，选择 plugins 中尚未使用的最小一代前缀
+func newInstanceIDs(plugins *plugin.PluginManager, pipelineName string) *instanceIDs {
+	var instances []plugin.InstanceInfo
+	if plugins != nil {
+		instances = plugins.Instances()
+	}
+	used := func(prefix string) bool {
+		for _, info := range instances {
+			if strings.HasPrefix(info.ID, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	prefix := pipelineName
+	for generation := 2; used(prefix); generation++ {
+		prefix = fmt.Sprintf("%s@%d", pipelineName, generation)
+	}
+	return &instanceIDs{prefix: prefix, seen: make(map[string]int)}
+}
+
+// next 返回插件的下一个实例 ID
+func (s *instanceIDs) next(name string) string {
+	s.seen[name]++
+	if n := s.seen[name]; n > 1 {
+		return fmt.Sprintf("%s/%s#%d", s.prefix, name, n)
+	}
+	return fmt.Sprintf("%s/%s", s.prefix, name)
 }
@@ -1,23 +1,38 @@
 package components
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
 )
 
 // MessagePanelModel 消息面板模型
 type MessagePanelModel struct {
 	viewport    viewport.Model
-	messages    []string
+	messages    []panelMessage
 	maxMessages int
 	width       int
 	height      int
+
+	// 选择模式：选择一条消息以屏蔽其发送者
+	selecting bool
+	cursor    int
+}
+
+// panelMessage 面板中的一条消息
+type panelMessage struct {
+	content string
+	author  *blocklist.User // 发送者，无法识别时为 nil
 }
 
 // AddMessageMsg 添加消息的消息类型
 type AddMessageMsg struct {
 	Content string
+	Author  *blocklist.User // 发送者，用于屏蔽，可为 nil
 }
 
 // NewMessagePanel 创建消息面板
@@ -27,7 +42,7 @@ func NewMessagePanel() MessagePanelModel {
 
 	return MessagePanelModel{
 		viewport:    vp,
-		messages:    []string{},
+		messages:    []panelMessage{},
 		maxMessages: 100,
 	}
 }
@@ -49,8 +64,13 @@ func (m MessagePanelModel) Update(msg tea.Msg) (MessagePanelModel, tea.Cmd) {
 		m.viewport.Height = m.height - 2
 
 	case AddMessageMsg:
-		m.addMessage(msg.Content)
+		m.addMessage(panelMessage{content: msg.Content, author: msg.Author})
 		return m, nil
+
+	case tea.KeyMsg:
+		if m.selecting {
+			return m.handleSelectKey(msg)
+		}
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
@@ -67,18 +87,117 @@ func (m MessagePanelModel) View() string {
 		Render(m.viewport.View())
 }
 
+// StartSelecting 进入选择模式，光标位于最新一条可识别发送者的消息
+//
+// 没有可选择的消息时返回 false。
+func (m MessagePanelModel) StartSelecting() (MessagePanelModel, bool) {
+	cursor := m.findAuthor(len(m.messages)-1, -1)
+	if cursor < 0 {
+		return m, false
+	}
+
+	m.selecting = true
+	m.cursor = cursor
+	m.render()
+	return m, true
+}
+
+// IsSelecting 返回是否处于选择模式
+func (m MessagePanelModel) IsSelecting() bool {
+	return m.selecting
+}
+
+// handleSelectKey 处理选择模式下的按键
+func (m MessagePanelModel) handleSelectKey(msg tea.KeyMsg) (MessagePanelModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if i := m.findAuthor(m.cursor-1, -1); i >= 0 {
+			m.cursor = i
+		}
+
+	case "down", "j":
+		if i := m.findAuthor(m.cursor+1, 1); i >= 0 {
+			m.cursor = i
+		}
+
+	case "enter", "b":
+		user := *m.messages[m.cursor].author
+		m.stopSelecting()
+		return m, func() tea.Msg {
+			return tuimsg.BlockUserRequestMsg{User: user}
+		}
+
+	case "esc":
+		m.stopSelecting()
+		return m, nil
+	}
+
+	m.render()
+	return m, nil
+}
+
+// stopSelecting 退出选择模式并回到底部
+func (m *MessagePanelModel) stopSelecting() {
+	m.selecting = false
+	m.render()
+	m.viewport.GotoBottom()
+}
+
+// findAuthor 从 start 开始按 step 方向查找可识别发送者的消息，找不到时返回 -1
+func (m MessagePanelModel) findAuthor(start, step int) int {
+	for i := start; i >= 0 && i < len(m.messages); i += step {
+		if m.messages[i].author != nil {
+			return i
+		}
+	}
+	return -1
+}
+
 // addMessage 添加消息
-func (m *MessagePanelModel) addMessage(msg string) {
+func (m *MessagePanelModel) addMessage(msg panelMessage) {
 	m.messages = append(m.messages, msg)
 	if len(m.messages) > m.maxMessages {
 		m.messages = m.messages[1:]
+
+		// 选中的消息被移出时退出选择模式
+		if m.selecting {
+			m.cursor--
+			if m.cursor < 0 {
+				m.selecting = false
+			}
+		}
 	}
 
-	// 更新 viewport 内容
-	content := ""
-	for _, message := range m.messages {
-		content += message + "\n"
+	m.render()
+	if !m.selecting {
+		m.viewport.GotoBottom()
+	}
+}
+
+// render 更新 viewport 内容，选择模式下标记选中的消息并保持其可见
+func (m *MessagePanelModel) render() {
+	markerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+
+	var content strings.Builder
+	for i, message := range m.messages {
+		if m.selecting {
+			if i == m.cursor {
+				content.WriteString(markerStyle.Render("> "))
+			} else {
+				content.WriteString("  ")
+			}
+		}
+		content.WriteString(message.content)
+		content.WriteString("\n")
+	}
+	m.viewport.SetContent(content.String())
+
+	if !m.selecting {
+		return
+	}
+	if m.cursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.cursor)
+	} else if m.cursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.cursor - m.viewport.Height + 1)
 	}
-	m.viewport.SetContent(content)
-	m.viewport.GotoBottom()
 }
@@ -4,20 +4,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
 	"gopkg.in/yaml.v3"
 )
 
-// 可用的消息类型
-var availableMessageTypes = []string{"Chat", "Gift", "Like", "EnterRoom", "Subscribe", "SuperChat", "EndLive"}
+// defaultStageID 默认配置中共享格式化阶段的 ID
+const defaultStageID = "format"
 
 // AppConfig 应用配置
 type AppConfig struct {
 	Server   ServerConfig   `yaml:"server"`
 	Client   ClientConfig   `yaml:"client"`
 	Pipeline PipelineConfig `yaml:"pipeline"`
+	Metrics  MetricsConfig  `yaml:"metrics"`
 }
 
 // ServerConfig 服务器配置
@@ -33,9 +36,22 @@ type ClientConfig struct {
 	Debug    bool   `yaml:"debug"`
 }
 
+// MetricsConfig 指标配置
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用 Prometheus 指标端点
+	Address string `yaml:"address"` // 监听地址（如：127.0.0.1:9464）
+}
+
 // PipelineConfig 管道配置
 type PipelineConfig struct {
-	Plugins []tuimsg.PluginConfig `yaml:"plugins"`
+	QueueSize        int                        `yaml:"queue_size,omitempty"`        // 每个管道及消费者的队列长度
+	OverflowPolicy   string                     `yaml:"overflow_policy,omitempty"`   // 队列溢出策略：block, drop_newest, drop_oldest
+	BreakerThreshold int                        `yaml:"breaker_threshold,omitempty"` // 插件连续失败多少次后熔断
+	BreakerCooldown  time.Duration              `yaml:"breaker_cooldown,omitempty"`  // 熔断冷却时间（如：30s）
+	DeadLetter       bool                       `yaml:"dead_letter"`                 // 是否把重试耗尽的消息写入死信文件
+	Stages           []tuimsg.SharedStageConfig `yaml:"stages,omitempty"`            // 共享阶段，插件通过 input 引用
+	ExecPlugins      []tuimsg.ExecPluginConfig  `yaml:"exec_plugins,omitempty"`      // 外部进程插件定义（修改后需重启）
+	Plugins          []tuimsg.PluginConfig      `yaml:"plugins"`
 }
 
 // GetConfigPath 获取配置文件路径
@@ -51,8 +67,38 @@ func GetConfigPath() (string, error) {
 	return configFile, nil
 }
 
-// ensureConfigDir 确保配置目录存在
-func ensureConfigDir() error {
+// GetLogPath 获取日志文件路径
+func GetLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".dmnotifier", "dmnotifier.log"), nil
+}
+
+// GetDeadLetterPath 获取死信文件路径
+func GetDeadLetterPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".dmnotifier", "deadletter.jsonl"), nil
+}
+
+// GetBlockListPath 获取用户屏蔽列表文件路径
+func GetBlockListPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".dmnotifier", "blocklist.json"), nil
+}
+
+// EnsureConfigDir 确保配置目录存在
+func EnsureConfigDir() error {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
@@ -96,7 +142,7 @@ func LoadConfig() (*AppConfig, error) {
 // SaveConfig 保存配置文件
 func SaveConfig(config *AppConfig) error {
 	// 确保配置目录存在
-	if err := ensureConfigDir(); err != nil {
+	if err := EnsureConfigDir(); err != nil {
 		return err
 	}
 
@@ -132,8 +178,24 @@ func GetDefaultConfig() *AppConfig {
 			Debug:    false,
 		},
 		Pipeline: PipelineConfig{
+			QueueSize:        256,
+			OverflowPolicy:   "drop_oldest",
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+			DeadLetter:       true,
+			Stages: []tuimsg.SharedStageConfig{
+				{
+					ID:         defaultStageID,
+					Filters:    []tuimsg.StageConfig{{Name: "user_block_filter"}},
+					Transforms: []tuimsg.StageConfig{{Name: "currency_transform"}, {Name: "format_transform"}},
+				},
+			},
 			Plugins: loadPluginConfigs(),
 		},
+		Metrics: MetricsConfig{
+			Enabled: false,
+			Address: "127.0.0.1:9464",
+		},
 	}
 }
 
@@ -156,7 +218,8 @@ func loadPluginConfigs() []tuimsg.PluginConfig {
 		configs = append(configs, tuimsg.PluginConfig{
 			Name:         info.Name,
 			Enabled:      true,
-			MessageTypes: append([]string{}, availableMessageTypes...), // 创建副本，避免共享地址
+			MessageTypes: models.TypeOptions(),
+			Input:        defaultStageID,
 			Config:       config,
 		})
 	}
@@ -0,0 +1,183 @@
+package popups
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+)
+
+// blockListPageSize 列表一次显示的记录数
+const blockListPageSize = 10
+
+// BlockListPopupModel 用户屏蔽列表弹窗
+type BlockListPopupModel struct {
+	visible bool
+	entries []blocklist.Entry
+	loading bool
+	cursor  int
+	width   int
+	height  int
+}
+
+// NewBlockListPopup 创建用户屏蔽列表弹窗
+func NewBlockListPopup() BlockListPopupModel {
+	return BlockListPopupModel{}
+}
+
+func (m BlockListPopupModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m BlockListPopupModel) Update(msg tea.Msg) (BlockListPopupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tuimsg.ShowBlockListPopupMsg:
+		m.visible = true
+		m.loading = true
+		return m, func() tea.Msg {
+			return tuimsg.LoadBlockListRequestMsg{}
+		}
+
+	case tuimsg.HidePopupMsg:
+		m.visible = false
+		return m, nil
+
+	case tuimsg.BlockListLoadedMsg:
+		m.loading = false
+		m.entries = msg.Entries
+		if m.cursor >= len(m.entries) {
+			m.cursor = len(m.entries) - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if !m.visible {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+
+		case "x", "delete":
+			if m.loading || len(m.entries) == 0 {
+				return m, nil
+			}
+			user := m.entries[m.cursor].User
+			m.loading = true
+			return m, func() tea.Msg {
+				return tuimsg.UnblockUserRequestMsg{User: user}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m BlockListPopupModel) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	width := 70
+	if m.width > 0 && m.width < 80 {
+		width = m.width - 10
+	}
+
+	primaryColor := lipgloss.Color("#7D56F4")
+	dimColor := lipgloss.Color("#666666")
+	foregroundColor := lipgloss.Color("#FFFFFF")
+
+	popupStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 2)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor).
+		Padding(0, 1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(foregroundColor)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(dimColor)
+
+	header := headerStyle.Width(width - 4).Render(fmt.Sprintf("Blocked Users (%d)", len(m.entries)))
+
+	var lines []string
+	switch {
+	case m.loading:
+		lines = append(lines, dimStyle.Render("Loading..."))
+
+	case len(m.entries) == 0:
+		lines = append(lines, dimStyle.Render("No blocked users - press b on the main screen to block a message author"))
+
+	default:
+		// 保持光标可见的滚动窗口
+		start := 0
+		if m.cursor >= blockListPageSize {
+			start = m.cursor - blockListPageSize + 1
+		}
+		end := start + blockListPageSize
+		if end > len(m.entries) {
+			end = len(m.entries)
+		}
+
+		for i := start; i < end; i++ {
+			entry := m.entries[i]
+			line := fmt.Sprintf("%s %s", entry.Added.Format("01-02 15:04"), entry.User)
+			if entry.UserID != "" {
+				line += fmt.Sprintf(" (id %s)", entry.UserID)
+			}
+			line = truncate(line, width-8)
+
+			if i == m.cursor {
+				lines = append(lines, selectedStyle.Render("> "+line))
+			} else {
+				lines = append(lines, normalStyle.Render("  "+line))
+			}
+		}
+	}
+
+	help := dimStyle.Render("Up/Down: Select | x: Unblock | Esc: Close")
+
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		help,
+	)
+
+	return popupStyle.
+		Width(width).
+		Render(body)
+}
+
+func (m BlockListPopupModel) IsVisible() bool {
+	return m.visible
+}
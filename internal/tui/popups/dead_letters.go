@@ -0,0 +1,205 @@
+package popups
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/deadletter"
+)
+
+// deadLettersPageSize 列表一次显示的记录数
+const deadLettersPageSize = 10
+
+// DeadLettersPopupModel 死信查看弹窗
+type DeadLettersPopupModel struct {
+	visible bool
+	entries []deadletter.Entry
+	err     error
+	loading bool
+	cursor  int
+	width   int
+	height  int
+}
+
+// NewDeadLettersPopup 创建死信查看弹窗
+func NewDeadLettersPopup() DeadLettersPopupModel {
+	return DeadLettersPopupModel{}
+}
+
+func (m DeadLettersPopupModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m DeadLettersPopupModel) Update(msg tea.Msg) (DeadLettersPopupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tuimsg.ShowDeadLettersPopupMsg:
+		m.visible = true
+		m.loading = true
+		return m, func() tea.Msg {
+			return tuimsg.LoadDeadLettersRequestMsg{}
+		}
+
+	case tuimsg.HidePopupMsg:
+		m.visible = false
+		return m, nil
+
+	case tuimsg.DeadLettersLoadedMsg:
+		m.loading = false
+		m.entries = msg.Entries
+		m.err = msg.Err
+		if m.cursor >= len(m.entries) {
+			m.cursor = 0
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if !m.visible {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+
+		case "r":
+			m.loading = true
+			return m, func() tea.Msg {
+				return tuimsg.LoadDeadLettersRequestMsg{}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m DeadLettersPopupModel) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	width := 80
+	if m.width > 0 && m.width < 90 {
+		width = m.width - 10
+	}
+
+	primaryColor := lipgloss.Color("#7D56F4")
+	dimColor := lipgloss.Color("#666666")
+	foregroundColor := lipgloss.Color("#FFFFFF")
+	errorColor := lipgloss.Color("#FF5F87")
+
+	popupStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 2)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor).
+		Padding(0, 1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(foregroundColor)
+
+	dimStyle := lipgloss.NewStyle().
+		Foreground(dimColor)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(errorColor)
+
+	header := headerStyle.Width(width - 4).Render(fmt.Sprintf("Dead Letters (%d)", len(m.entries)))
+
+	var lines []string
+	switch {
+	case m.loading:
+		lines = append(lines, dimStyle.Render("Loading..."))
+
+	case m.err != nil:
+		lines = append(lines, errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+
+	case len(m.entries) == 0:
+		lines = append(lines, dimStyle.Render("No failed messages"))
+
+	default:
+		// 保持光标可见的滚动窗口
+		start := 0
+		if m.cursor >= deadLettersPageSize {
+			start = m.cursor - deadLettersPageSize + 1
+		}
+		end := start + deadLettersPageSize
+		if end > len(m.entries) {
+			end = len(m.entries)
+		}
+
+		for i := start; i < end; i++ {
+			entry := m.entries[i]
+			line := fmt.Sprintf("%s %s/%s x%d", entry.Time.Format("01-02 15:04:05"), entry.Pipeline, entry.Plugin, entry.Attempts)
+			line = truncate(line, width-8)
+
+			if i == m.cursor {
+				lines = append(lines, selectedStyle.Render("> "+line))
+			} else {
+				lines = append(lines, normalStyle.Render("  "+line))
+			}
+		}
+
+		// 当前记录详情
+		entry := m.entries[m.cursor]
+		lines = append(lines, "")
+		lines = append(lines, errorStyle.Render(truncate("Error: "+entry.Error, width-6)))
+		if entry.Message != nil {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("Message: %s %s/%s", entry.Message.Type, entry.Message.Platform, entry.Message.RID)))
+		}
+		if entry.Content != "" {
+			lines = append(lines, dimStyle.Render(truncate("Content: "+entry.Content, width-6)))
+		}
+	}
+
+	help := dimStyle.Render("Up/Down: Select | r: Reload | Esc: Close")
+
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		help,
+	)
+
+	return popupStyle.
+		Width(width).
+		Render(body)
+}
+
+func (m DeadLettersPopupModel) IsVisible() bool {
+	return m.visible
+}
+
+// truncate 按字符截断文本
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
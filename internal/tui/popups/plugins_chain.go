@@ -0,0 +1,393 @@
+package popups
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+)
+
+// chainEditor 过滤器/转换器链编辑状态
+type chainEditor struct {
+	kind         plugin.PluginType // 正在编辑的链类型，空表示未编辑
+	cursor       int               // 阶段列表光标
+	adding       bool              // 是否在选择要添加的插件
+	addCursor    int               // 可添加插件列表光标
+	stageEditing bool              // 是否在编辑阶段配置
+	fieldCursor  int               // 阶段配置字段光标
+	fieldEditing bool              // 是否在输入字段值
+}
+
+// newChainEditor 创建未激活的链编辑状态
+func newChainEditor() chainEditor {
+	return chainEditor{}
+}
+
+// active 返回是否正在编辑链
+func (c chainEditor) active() bool {
+	return c.kind != ""
+}
+
+// help 返回当前编辑状态的帮助文本
+func (c chainEditor) help() string {
+	switch {
+	case c.fieldEditing:
+		return "Enter: Save | Esc: Cancel"
+	case c.stageEditing:
+		return "Up/Down: Navigate | Enter: Edit | Esc: Back"
+	case c.adding:
+		return "Up/Down: Navigate | Enter: Add | Esc: Cancel"
+	default:
+		return "Up/Down: Navigate | a: Add | x: Remove | K/J: Move | Enter: Config | Esc: Back"
+	}
+}
+
+// currentChain 返回当前插件正在编辑的链
+func (m *PluginsConfigModel) currentChain() *[]tuimsg.StageConfig {
+	if m.pluginCursor >= len(m.plugins) {
+		return nil
+	}
+
+	pluginCfg := &m.plugins[m.pluginCursor]
+	if m.chain.kind == plugin.TypeFilter {
+		return &pluginCfg.Filters
+	}
+
+	// 未配置转换器链时展开为默认链，便于在其基础上增删
+	if pluginCfg.Transforms == nil && pluginCfg.Input == "" {
+		pluginCfg.Transforms = []tuimsg.StageConfig{{Name: "format_transform"}}
+	}
+	return &pluginCfg.Transforms
+}
+
+// chainCandidates 返回可添加到当前链的插件
+func (m PluginsConfigModel) chainCandidates() []plugin.PluginInfo {
+	infos := plugin.GlobalRegistry.GetPluginInfoByType(m.chain.kind)
+	candidates := make([]plugin.PluginInfo, 0, len(infos))
+	for _, info := range infos {
+		// 消息类型过滤器由 Types 行管理
+		if info.Name == "message_type_filter" {
+			continue
+		}
+		candidates = append(candidates, info)
+	}
+	return candidates
+}
+
+// chainChanged 返回链修改后的状态提示和配置更新命令
+func (m PluginsConfigModel) chainChanged(status string) tea.Cmd {
+	return tea.Batch(
+		func() tea.Msg {
+			return tuimsg.StatusMsg{Message: status}
+		},
+		func() tea.Msg {
+			return tuimsg.UpdatePluginsConfigMsg{Plugins: m.plugins}
+		},
+	)
+}
+
+// handleChainEditing 处理过滤器/转换器链编辑
+func (m PluginsConfigModel) handleChainEditing(msg tea.KeyMsg) (PluginsConfigModel, tea.Cmd) {
+	chain := m.currentChain()
+	if chain == nil {
+		m.chain = newChainEditor()
+		return m, nil
+	}
+
+	if m.chain.stageEditing {
+		return m.handleStageConfigEditing(msg, chain)
+	}
+
+	if m.chain.adding {
+		candidates := m.chainCandidates()
+
+		switch msg.String() {
+		case "esc":
+			m.chain.adding = false
+
+		case "up", "k":
+			if m.chain.addCursor > 0 {
+				m.chain.addCursor--
+			}
+
+		case "down", "j":
+			if m.chain.addCursor < len(candidates)-1 {
+				m.chain.addCursor++
+			}
+
+		case "enter":
+			if m.chain.addCursor >= len(candidates) {
+				return m, nil
+			}
+
+			info := candidates[m.chain.addCursor]
+			var config map[string]interface{}
+			if len(info.ConfigTemplate) > 0 {
+				config = make(map[string]interface{})
+				for _, field := range info.ConfigTemplate {
+					config[field.Name] = field.Default
+				}
+			}
+
+			*chain = append(*chain, tuimsg.StageConfig{Name: info.Name, Config: config})
+			m.chain.adding = false
+			m.chain.cursor = len(*chain) - 1
+			return m, m.chainChanged(fmt.Sprintf("Added %s", info.Name))
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.chain = newChainEditor()
+		return m, func() tea.Msg {
+			return tuimsg.StatusMsg{Message: "Exit chain editing"}
+		}
+
+	case "up", "k":
+		if m.chain.cursor > 0 {
+			m.chain.cursor--
+		}
+
+	case "down", "j":
+		if m.chain.cursor < len(*chain)-1 {
+			m.chain.cursor++
+		}
+
+	case "a":
+		m.chain.adding = true
+		m.chain.addCursor = 0
+
+	case "x", "delete":
+		if m.chain.cursor < len(*chain) {
+			name := (*chain)[m.chain.cursor].Name
+			*chain = append((*chain)[:m.chain.cursor], (*chain)[m.chain.cursor+1:]...)
+			if m.chain.cursor >= len(*chain) && m.chain.cursor > 0 {
+				m.chain.cursor--
+			}
+			return m, m.chainChanged(fmt.Sprintf("Removed %s", name))
+		}
+
+	case "K":
+		if m.chain.cursor > 0 && m.chain.cursor < len(*chain) {
+			i := m.chain.cursor
+			(*chain)[i-1], (*chain)[i] = (*chain)[i], (*chain)[i-1]
+			m.chain.cursor--
+			return m, m.chainChanged(fmt.Sprintf("Moved %s up", (*chain)[i-1].Name))
+		}
+
+	case "J":
+		if m.chain.cursor < len(*chain)-1 {
+			i := m.chain.cursor
+			(*chain)[i+1], (*chain)[i] = (*chain)[i], (*chain)[i+1]
+			m.chain.cursor++
+			return m, m.chainChanged(fmt.Sprintf("Moved %s down", (*chain)[i+1].Name))
+		}
+
+	case "enter":
+		if m.chain.cursor >= len(*chain) {
+			return m, nil
+		}
+
+		stage := (*chain)[m.chain.cursor]
+		if len(getPluginConfigTemplate(stage.Name)) == 0 {
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: fmt.Sprintf("%s has no config fields", stage.Name)}
+			}
+		}
+		m.chain.stageEditing = true
+		m.chain.fieldCursor = 0
+	}
+
+	return m, nil
+}
+
+// handleStageConfigEditing 处理阶段配置编辑
+func (m PluginsConfigModel) handleStageConfigEditing(msg tea.KeyMsg, chain *[]tuimsg.StageConfig) (PluginsConfigModel, tea.Cmd) {
+	if m.chain.cursor >= len(*chain) {
+		m.chain.stageEditing = false
+		return m, nil
+	}
+
+	stage := &(*chain)[m.chain.cursor]
+	template := getPluginConfigTemplate(stage.Name)
+	if m.chain.fieldCursor >= len(template) {
+		m.chain.stageEditing = false
+		return m, nil
+	}
+	field := template[m.chain.fieldCursor]
+
+	if m.chain.fieldEditing {
+		switch msg.String() {
+		case "esc":
+			m.chain.fieldEditing = false
+			m.pluginConfigInput.Blur()
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: "Cancelled"}
+			}
+
+		case "enter":
+			newValue := m.pluginConfigInput.Value()
+			value, err := parseFieldValue(field, newValue)
+			if err != nil {
+				return m, func() tea.Msg {
+					return tuimsg.StatusMsg{Message: err.Error()}
+				}
+			}
+
+			if stage.Config == nil {
+				stage.Config = make(map[string]interface{})
+			}
+			stage.Config[field.Name] = value
+			m.chain.fieldEditing = false
+			m.pluginConfigInput.Blur()
+			return m, m.chainChanged(fmt.Sprintf("Saved %s.%s = %s", stage.Name, field.Name, newValue))
+
+		default:
+			var cmd tea.Cmd
+			m.pluginConfigInput, cmd = m.pluginConfigInput.Update(msg)
+			return m, cmd
+		}
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.chain.stageEditing = false
+
+	case "up", "k":
+		if m.chain.fieldCursor > 0 {
+			m.chain.fieldCursor--
+		}
+
+	case "down", "j":
+		if m.chain.fieldCursor < len(template)-1 {
+			m.chain.fieldCursor++
+		}
+
+	case "enter", " ":
+		switch field.Type {
+		case plugin.FieldTypeBool:
+			if stage.Config == nil {
+				stage.Config = make(map[string]interface{})
+			}
+			val, _ := stage.Config[field.Name].(bool)
+			stage.Config[field.Name] = !val
+			return m, m.chainChanged(fmt.Sprintf("Toggled %s.%s", stage.Name, field.Name))
+
+		case plugin.FieldTypeEnum:
+			if stage.Config == nil {
+				stage.Config = make(map[string]interface{})
+			}
+			value := nextEnumOption(field, stage.Config[field.Name])
+			stage.Config[field.Name] = value
+			return m, m.chainChanged(fmt.Sprintf("Set %s.%s = %s", stage.Name, field.Name, value))
+
+		case plugin.FieldTypeString, plugin.FieldTypeNumber:
+			currentVal := ""
+			if val, ok := stage.Config[field.Name]; ok && val != nil {
+				currentVal = fmt.Sprintf("%v", val)
+			}
+			m.pluginConfigInput.SetValue(currentVal)
+			m.pluginConfigInput.Focus()
+			m.pluginConfigInput.StartEdit()
+			m.chain.fieldEditing = true
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: fmt.Sprintf("Editing %s (Enter: save, Esc: cancel)", field.Name)}
+			}
+
+		default:
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: fmt.Sprintf("Type %s not supported yet", field.Type)}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// renderChainEditor 渲染过滤器/转换器链编辑器
+func (m PluginsConfigModel) renderChainEditor() string {
+	chain := m.currentChain()
+	if chain == nil {
+		return ""
+	}
+
+	pluginName := m.plugins[m.pluginCursor].Name
+	var items []string
+
+	title := "Filter chain"
+	if m.chain.kind == plugin.TypeTransform {
+		title = "Transform chain"
+	}
+
+	if m.chain.adding {
+		items = append(items, m.itemStyle.Bold(true).Render(fmt.Sprintf("Add %s to %s", m.chain.kind, pluginName)))
+		items = append(items, "")
+
+		candidates := m.chainCandidates()
+		if len(candidates) == 0 {
+			items = append(items, m.dimStyle.Render(fmt.Sprintf("  No %s plugins available", m.chain.kind)))
+		}
+		for i, info := range candidates {
+			line := "  " + info.Name
+			if i == m.chain.addCursor {
+				items = append(items, m.selectedStyle.Render("> "+info.Name))
+			} else {
+				items = append(items, m.itemStyle.Render(line))
+			}
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, items...)
+	}
+
+	if m.chain.stageEditing && m.chain.cursor < len(*chain) {
+		stage := (*chain)[m.chain.cursor]
+		items = append(items, m.itemStyle.Bold(true).Render(fmt.Sprintf("Config for %s (%s)", stage.Name, pluginName)))
+		items = append(items, "")
+
+		for i, field := range getPluginConfigTemplate(stage.Name) {
+			cursor := "  "
+			if i == m.chain.fieldCursor {
+				cursor = "> "
+			}
+
+			if i == m.chain.fieldCursor && m.chain.fieldEditing {
+				label := fmt.Sprintf("%s%s: ", cursor, field.Name)
+				items = append(items, m.selectedStyle.Render(label)+m.pluginConfigInput.View())
+				continue
+			}
+
+			line := fmt.Sprintf("%s%s: %s", cursor, field.Name, formatFieldValue(field, stage.Config[field.Name]))
+			if i == m.chain.fieldCursor {
+				items = append(items, m.selectedStyle.Render(line))
+				if field.Desc != "" {
+					items = append(items, m.dimStyle.Render("    "+field.Desc))
+				}
+			} else {
+				items = append(items, m.itemStyle.Foreground(m.dimColor).Render(line))
+			}
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, items...)
+	}
+
+	items = append(items, m.itemStyle.Bold(true).Render(fmt.Sprintf("%s for: %s", title, pluginName)))
+	items = append(items, "")
+
+	if len(*chain) == 0 {
+		items = append(items, m.dimStyle.Render("  (empty) - press a to add"))
+	}
+	for i, stage := range *chain {
+		line := fmt.Sprintf("%d. %s", i+1, stage.Name)
+		if len(stage.Config) > 0 {
+			line += m.dimStyle.Render(fmt.Sprintf(" (%d settings)", len(stage.Config)))
+		}
+		if i == m.chain.cursor {
+			items = append(items, m.selectedStyle.Render("> "+line))
+		} else {
+			items = append(items, m.itemStyle.Render("  "+line))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, items...)
+}
@@ -3,16 +3,27 @@ package popups
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/internal/tui/components"
+	"github.com/xifan2333/dmnotifier/pkg/models"
 )
 
-// 可用的消息类型
-var availableMessageTypes = []string{"Chat", "Gift", "Like", "EnterRoom", "Subscribe", "SuperChat", "EndLive"}
+// 可用的消息类型，Unknown 表示所有未知类型
+var availableMessageTypes = models.TypeOptions()
+
+// 插件内各行的索引
+const (
+	itemName        = 0 // 插件名
+	itemTypes       = 1 // 消息类型
+	itemFilters     = 2 // 过滤器链
+	itemTransforms  = 3 // 转换器链
+	itemFieldsStart = 4 // 配置字段起始
+)
 
 // PluginsConfigModel 插件配置弹窗模型
 type PluginsConfigModel struct {
@@ -25,7 +36,7 @@ type PluginsConfigModel struct {
 
 	// 导航状态
 	pluginCursor     int // 当前选中的插件索引
-	pluginItemCursor int // 当前插件内的项目索引 (0=name, 1=types, 2+=config fields)
+	pluginItemCursor int // 当前插件内的项目索引（见 itemName 等常量）
 
 	// 编辑状态
 	pluginEditingTypes bool // 是否正在编辑消息类型
@@ -34,6 +45,9 @@ type PluginsConfigModel struct {
 	pluginEditingField int                       // 正在编辑的字段索引 (-1 表示未编辑)
 	pluginConfigInput  components.FormInputModel // 配置字段输入框
 
+	// 过滤器/转换器链编辑状态
+	chain chainEditor
+
 	// 样式
 	primaryColor    lipgloss.Color
 	foregroundColor lipgloss.Color
@@ -60,6 +74,7 @@ func NewPluginsConfig() PluginsConfigModel {
 		pluginTypesCursor:  0,
 		pluginEditingField: -1,
 		pluginConfigInput:  components.NewFormInput("", "", 200),
+		chain:              newChainEditor(),
 		primaryColor:       primaryColor,
 		foregroundColor:    foregroundColor,
 		dimColor:           dimColor,
@@ -99,12 +114,14 @@ func (m PluginsConfigModel) Update(msg tea.Msg) (PluginsConfigModel, tea.Cmd) {
 		m.pluginItemCursor = 0
 		m.pluginEditingTypes = false
 		m.pluginEditingField = -1
+		m.chain = newChainEditor()
 		return m, nil
 
 	case tuimsg.HidePopupMsg:
 		m.visible = false
 		m.pluginEditingTypes = false
 		m.pluginEditingField = -1
+		m.chain = newChainEditor()
 		m.pluginConfigInput.Blur()
 		return m, nil
 
@@ -136,6 +153,11 @@ func (m PluginsConfigModel) handleKeyPress(msg tea.KeyMsg) (PluginsConfigModel,
 		return m.handleMessageTypesEditing(msg)
 	}
 
+	// 如果在编辑过滤器/转换器链
+	if m.chain.active() {
+		return m.handleChainEditing(msg)
+	}
+
 	// 普通导航模式
 	return m.handleNavigation(msg)
 }
@@ -169,21 +191,16 @@ func (m PluginsConfigModel) handleFieldEditing(msg tea.KeyMsg) (PluginsConfigMod
 		newValue := m.pluginConfigInput.Value()
 
 		// 根据类型转换值
-		switch field.Type {
-		case plugin.FieldTypeString:
-			pluginCfg.Config[field.Name] = newValue
-		case plugin.FieldTypeNumber:
-			// 尝试解析为数字
-			if intVal, err := strconv.Atoi(newValue); err == nil {
-				pluginCfg.Config[field.Name] = intVal
-			} else if floatVal, err := strconv.ParseFloat(newValue, 64); err == nil {
-				pluginCfg.Config[field.Name] = floatVal
-			} else {
-				return m, func() tea.Msg {
-					return tuimsg.StatusMsg{Message: fmt.Sprintf("Invalid number: %s", newValue)}
-				}
+		value, err := parseFieldValue(field, newValue)
+		if err != nil {
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: err.Error()}
 			}
 		}
+		if pluginCfg.Config == nil {
+			pluginCfg.Config = make(map[string]interface{})
+		}
+		pluginCfg.Config[field.Name] = value
 
 		m.pluginEditingField = -1
 		m.pluginConfigInput.Blur()
@@ -288,7 +305,7 @@ func (m PluginsConfigModel) handleNavigation(msg tea.KeyMsg) (PluginsConfigModel
 
 	case " ":
 		// 只在插件名行才能切换启用/禁用
-		if m.pluginItemCursor == 0 && m.pluginCursor < len(m.plugins) {
+		if m.pluginItemCursor == itemName && m.pluginCursor < len(m.plugins) {
 			m.plugins[m.pluginCursor].Enabled = !m.plugins[m.pluginCursor].Enabled
 			status := "disabled"
 			if m.plugins[m.pluginCursor].Enabled {
@@ -313,21 +330,35 @@ func (m PluginsConfigModel) handleNavigation(msg tea.KeyMsg) (PluginsConfigModel
 		template := getPluginConfigTemplate(pluginCfg.Name)
 
 		switch m.pluginItemCursor {
-		case 0:
+		case itemName:
 			// 在插件名上，不做任何事
 			return m, func() tea.Msg {
 				return tuimsg.StatusMsg{Message: "Use Space to toggle enable/disable"}
 			}
-		case 1:
+		case itemTypes:
 			// 在 Types 行，进入消息类型编辑
 			m.pluginEditingTypes = true
 			m.pluginTypesCursor = 0
 			return m, func() tea.Msg {
 				return tuimsg.StatusMsg{Message: "Editing message types (Space: toggle, Esc: exit)"}
 			}
+		case itemFilters:
+			// 在 Filters 行，进入过滤器链编辑
+			m.chain = newChainEditor()
+			m.chain.kind = plugin.TypeFilter
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: "Editing filter chain (a: add, x: remove, K/J: move, Enter: config, Esc: exit)"}
+			}
+		case itemTransforms:
+			// 在 Transforms 行，进入转换器链编辑
+			m.chain = newChainEditor()
+			m.chain.kind = plugin.TypeTransform
+			return m, func() tea.Msg {
+				return tuimsg.StatusMsg{Message: "Editing transform chain (a: add, x: remove, K/J: move, Enter: config, Esc: exit)"}
+			}
 		default:
 			// 在配置字段行
-			fieldIdx := m.pluginItemCursor - 2
+			fieldIdx := m.pluginItemCursor - itemFieldsStart
 			if fieldIdx < len(template) {
 				field := template[fieldIdx]
 
@@ -346,6 +377,22 @@ func (m PluginsConfigModel) handleNavigation(msg tea.KeyMsg) (PluginsConfigModel
 						)
 					}
 
+				case plugin.FieldTypeEnum:
+					// Enum 类型循环切换选项
+					if pluginCfg.Config == nil {
+						pluginCfg.Config = make(map[string]interface{})
+					}
+					value := nextEnumOption(field, pluginCfg.Config[field.Name])
+					pluginCfg.Config[field.Name] = value
+					return m, tea.Batch(
+						func() tea.Msg {
+							return tuimsg.StatusMsg{Message: fmt.Sprintf("Set %s = %s", field.Name, value)}
+						},
+						func() tea.Msg {
+							return tuimsg.UpdatePluginsConfigMsg{Plugins: m.plugins}
+						},
+					)
+
 				case plugin.FieldTypeString, plugin.FieldTypeNumber:
 					// String/Number 类型进入输入模式
 					currentVal := fmt.Sprintf("%v", pluginCfg.Config[field.Name])
@@ -390,6 +437,9 @@ func (m PluginsConfigModel) View() string {
 	if m.pluginEditingTypes {
 		// 显示消息类型编辑界面
 		content = m.renderMessageTypesEditor()
+	} else if m.chain.active() {
+		// 显示过滤器/转换器链编辑界面
+		content = m.renderChainEditor()
 	} else {
 		// 显示插件列表
 		content = m.renderPluginsList()
@@ -398,6 +448,8 @@ func (m PluginsConfigModel) View() string {
 	var help string
 	if m.pluginEditingTypes {
 		help = m.dimStyle.Render("Up/Down: Navigate | Space: Toggle | Esc: Back")
+	} else if m.chain.active() {
+		help = m.dimStyle.Render(m.chain.help())
 	} else {
 		help = m.dimStyle.Render("Up/Down: Navigate | Space: Toggle Enable | Enter: Edit | Esc: Close")
 	}
@@ -422,27 +474,98 @@ func (m PluginsConfigModel) IsVisible() bool {
 	return m.visible
 }
 
+// IsEditing 返回是否处于字段、消息类型或链编辑状态
+func (m PluginsConfigModel) IsEditing() bool {
+	return m.pluginEditingField >= 0 || m.pluginEditingTypes || m.chain.active()
+}
+
 // getPluginItemCount 获取当前插件的项目总数
 func (m PluginsConfigModel) getPluginItemCount() int {
 	if m.pluginCursor >= len(m.plugins) {
 		return 0
 	}
 	template := getPluginConfigTemplate(m.plugins[m.pluginCursor].Name)
-	// 0=name, 1=types, 2+=config fields
-	return 2 + len(template)
+	return itemFieldsStart + len(template)
 }
 
 // getPluginConfigTemplate 获取插件的配置模板
 func getPluginConfigTemplate(pluginName string) []plugin.ConfigField {
-	pluginInfos := plugin.GlobalRegistry.GetAllPluginInfo()
-	for _, info := range pluginInfos {
-		if info.Name == pluginName {
-			return info.ConfigTemplate
-		}
+	if info, ok := plugin.GlobalRegistry.GetPluginInfo(pluginName); ok {
+		return info.ConfigTemplate
 	}
 	return []plugin.ConfigField{}
 }
 
+// parseFieldValue 按字段类型解析输入值，并执行字段的校验函数
+func parseFieldValue(field plugin.ConfigField, input string) (interface{}, error) {
+	var value interface{} = input
+
+	if field.Type == plugin.FieldTypeNumber {
+		// 尝试解析为数字
+		if intVal, err := strconv.Atoi(input); err == nil {
+			value = intVal
+		} else if floatVal, err := strconv.ParseFloat(input, 64); err == nil {
+			value = floatVal
+		} else {
+			return nil, fmt.Errorf("Invalid number: %s", input)
+		}
+	}
+
+	if field.Validate != nil {
+		if err := field.Validate(value); err != nil {
+			return nil, fmt.Errorf("Invalid %s: %v", field.Name, err)
+		}
+	}
+
+	return value, nil
+}
+
+// nextEnumOption 返回枚举字段当前值的下一个选项（循环）
+func nextEnumOption(field plugin.ConfigField, current interface{}) string {
+	if len(field.Options) == 0 {
+		return ""
+	}
+
+	value, _ := current.(string)
+	for i, option := range field.Options {
+		if option == value {
+			return field.Options[(i+1)%len(field.Options)]
+		}
+	}
+	return field.Options[0]
+}
+
+// formatFieldValue 按字段类型格式化显示值
+func formatFieldValue(field plugin.ConfigField, value interface{}) string {
+	switch field.Type {
+	case plugin.FieldTypeBool:
+		if value == true {
+			return "[✓]"
+		}
+		return "[ ]"
+	case plugin.FieldTypeString:
+		valueStr := fmt.Sprintf("%v", value)
+		if value == nil || valueStr == "" {
+			return "(empty)"
+		}
+		return valueStr
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// formatStageNames 格式化阶段名称列表
+func formatStageNames(stages []tuimsg.StageConfig, empty string) string {
+	if len(stages) == 0 {
+		return empty
+	}
+	names := make([]string, len(stages))
+	for i, stage := range stages {
+		names[i] = stage.Name
+	}
+	return strings.Join(names, " → ")
+}
+
 // renderPluginsList 渲染插件列表
 func (m PluginsConfigModel) renderPluginsList() string {
 	var items []string
@@ -452,7 +575,7 @@ func (m PluginsConfigModel) renderPluginsList() string {
 
 		// 插件名行
 		cursor := "  "
-		if i == m.pluginCursor && m.pluginItemCursor == 0 {
+		if i == m.pluginCursor && m.pluginItemCursor == itemName {
 			cursor = "> "
 		}
 
@@ -462,7 +585,10 @@ func (m PluginsConfigModel) renderPluginsList() string {
 		}
 
 		pluginLine := fmt.Sprintf("%s%s %s", cursor, status, pluginCfg.Name)
-		if i == m.pluginCursor && m.pluginItemCursor == 0 {
+		if pluginCfg.Input != "" {
+			pluginLine += fmt.Sprintf(" ← %s", pluginCfg.Input)
+		}
+		if i == m.pluginCursor && m.pluginItemCursor == itemName {
 			items = append(items, m.selectedStyle.Render(pluginLine))
 		} else {
 			items = append(items, m.itemStyle.Render(pluginLine))
@@ -470,7 +596,7 @@ func (m PluginsConfigModel) renderPluginsList() string {
 
 		// Types 行
 		cursor = "  "
-		if i == m.pluginCursor && m.pluginItemCursor == 1 {
+		if i == m.pluginCursor && m.pluginItemCursor == itemTypes {
 			cursor = "> "
 		}
 
@@ -503,15 +629,37 @@ func (m PluginsConfigModel) renderPluginsList() string {
 		}
 
 		typesLine := fmt.Sprintf("%sTypes: %s", cursor, typesStr)
-		if i == m.pluginCursor && m.pluginItemCursor == 1 {
+		if i == m.pluginCursor && m.pluginItemCursor == itemTypes {
 			items = append(items, m.selectedStyle.Render(typesLine))
 		} else {
 			items = append(items, m.itemStyle.Foreground(m.dimColor).Render(typesLine))
 		}
 
+		// Filters / Transforms 行（接在共享阶段之后时没有默认转换器）
+		defaultTransforms := "(default: format_transform)"
+		if pluginCfg.Input != "" {
+			defaultTransforms = "(none)"
+		}
+		chainLines := []struct {
+			idx  int
+			line string
+		}{
+			{itemFilters, "Filters: " + formatStageNames(pluginCfg.Filters, "(none)")},
+			{itemTransforms, "Transforms: " + formatStageNames(pluginCfg.Transforms, defaultTransforms)},
+		}
+		for _, chainLine := range chainLines {
+			cursor = "  "
+			if i == m.pluginCursor && m.pluginItemCursor == chainLine.idx {
+				cursor = "> "
+				items = append(items, m.selectedStyle.Render(cursor+chainLine.line))
+			} else {
+				items = append(items, m.itemStyle.Foreground(m.dimColor).Render(cursor+chainLine.line))
+			}
+		}
+
 		// Config fields
 		for fieldIdx, field := range template {
-			itemIdx := 2 + fieldIdx
+			itemIdx := itemFieldsStart + fieldIdx
 			cursor = "  "
 			if i == m.pluginCursor && m.pluginItemCursor == itemIdx {
 				cursor = "> "
@@ -526,24 +674,7 @@ func (m PluginsConfigModel) renderPluginsList() string {
 				items = append(items, line)
 			} else {
 				// 根据类型格式化值
-				var valueStr string
-				switch field.Type {
-				case plugin.FieldTypeBool:
-					if value == true {
-						valueStr = "[✓]"
-					} else {
-						valueStr = "[ ]"
-					}
-				case plugin.FieldTypeNumber:
-					valueStr = fmt.Sprintf("%v", value)
-				case plugin.FieldTypeString:
-					valueStr = fmt.Sprintf("%v", value)
-					if valueStr == "" {
-						valueStr = "(empty)"
-					}
-				default:
-					valueStr = fmt.Sprintf("%v", value)
-				}
+				valueStr := formatFieldValue(field, value)
 
 				line := fmt.Sprintf("%s%s: %s", cursor, field.Name, valueStr)
 				if i == m.pluginCursor && m.pluginItemCursor == itemIdx {
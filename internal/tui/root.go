@@ -2,6 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -19,10 +22,24 @@ type RootModel struct {
 	serverConfig  popups.ServerConfigModel
 	addService    popups.AddServiceModel
 	pluginsConfig popups.PluginsConfigModel
+	deadLetters   popups.DeadLettersPopupModel
+	blockList     popups.BlockListPopupModel
 
 	// 当前连接的服务
 	selectedService *api.Service
 
+	// 非正常状态（熔断、试探中）的插件，键为 "管道/插件"
+	pluginStates map[string]string
+
+	// 消费者插件实例的生命周期状态，键为实例 ID
+	consumerHealth map[string]tuimsg.PluginHealthMsg
+
+	// 最近一次直播间统计的在线人数，-1 表示尚未收到
+	online int64
+
+	// WebSocket 连接状态，断开服务后清空
+	connection tuimsg.ConnectionStateMsg
+
 	// 配置
 	config *AppConfig
 
@@ -47,8 +64,14 @@ func NewRootModel(config *AppConfig) RootModel {
 		serverConfig:  serverConfig,
 		addService:    popups.NewAddService(),
 		pluginsConfig: popups.NewPluginsConfig(),
+		deadLetters:   popups.NewDeadLettersPopup(),
+		blockList:     popups.NewBlockListPopup(),
 		config:        config,
+		pluginStates:  make(map[string]string),
 		statusMessage: "Ready",
+		online:        -1,
+
+		consumerHealth: make(map[string]tuimsg.PluginHealthMsg),
 	}
 }
 
@@ -60,6 +83,7 @@ func (m RootModel) Init() tea.Cmd {
 		m.serverConfig.Init(),
 		m.addService.Init(),
 		m.pluginsConfig.Init(),
+		m.blockList.Init(),
 		// 发送请求刷新服务列表
 		func() tea.Msg {
 			return tuimsg.RefreshServicesRequestMsg{}
@@ -124,6 +148,8 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.pluginsConfig.IsVisible() {
+			editing := m.pluginsConfig.IsEditing()
+
 			var cmd tea.Cmd
 			m.pluginsConfig, cmd = m.pluginsConfig.Update(msg)
 			if cmd != nil {
@@ -131,13 +157,53 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			// Esc 关闭弹窗（只有在非编辑状态）
-			if msg.String() == "esc" {
+			if msg.String() == "esc" && !editing {
 				m.pluginsConfig, _ = m.pluginsConfig.Update(tuimsg.HidePopupMsg{})
 			}
 
 			return m, tea.Batch(cmds...)
 		}
 
+		if m.deadLetters.IsVisible() {
+			var cmd tea.Cmd
+			m.deadLetters, cmd = m.deadLetters.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+
+			// Esc 关闭弹窗
+			if msg.String() == "esc" {
+				m.deadLetters, _ = m.deadLetters.Update(tuimsg.HidePopupMsg{})
+			}
+
+			return m, tea.Batch(cmds...)
+		}
+
+		if m.blockList.IsVisible() {
+			var cmd tea.Cmd
+			m.blockList, cmd = m.blockList.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+
+			// Esc 关闭弹窗
+			if msg.String() == "esc" {
+				m.blockList, _ = m.blockList.Update(tuimsg.HidePopupMsg{})
+			}
+
+			return m, tea.Batch(cmds...)
+		}
+
+		// 消息选择模式下按键交给消息面板
+		if m.messagePanel.IsSelecting() {
+			var cmd tea.Cmd
+			m.messagePanel, cmd = m.messagePanel.Update(msg)
+			if !m.messagePanel.IsSelecting() && msg.String() == "esc" {
+				m.statusMessage = "Ready"
+			}
+			return m, cmd
+		}
+
 		// 主界面按键处理
 		switch msg.String() {
 		case "ctrl+c", "q":
@@ -165,6 +231,29 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			return m, nil
 
+		case "l":
+			// 显示死信弹窗
+			var cmd tea.Cmd
+			m.deadLetters, cmd = m.deadLetters.Update(tuimsg.ShowDeadLettersPopupMsg{})
+			return m, cmd
+
+		case "b":
+			// 选择消息以屏蔽其发送者
+			var ok bool
+			m.messagePanel, ok = m.messagePanel.StartSelecting()
+			if ok {
+				m.statusMessage = "Select a message: Up/Down to move, Enter to block its author, Esc to cancel"
+			} else {
+				m.statusMessage = "No messages to select"
+			}
+			return m, nil
+
+		case "B":
+			// 显示屏蔽列表弹窗
+			var cmd tea.Cmd
+			m.blockList, cmd = m.blockList.Update(tuimsg.ShowBlockListPopupMsg{})
+			return m, cmd
+
 		case "r":
 			// 刷新服务列表
 			m.statusMessage = "Refreshing services..."
@@ -186,10 +275,15 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tuimsg.ServiceConnectedMsg:
 		m.selectedService = msg.Service
+		m.online = -1
 		m.statusMessage = fmt.Sprintf("Connected to %s/%s", msg.Service.Platform, msg.Service.RID)
 
 	case tuimsg.ServiceDisconnectedMsg:
 		m.selectedService = nil
+		m.online = -1
+		m.connection = tuimsg.ConnectionStateMsg{}
+		m.pluginStates = make(map[string]string)
+		m.consumerHealth = make(map[string]tuimsg.PluginHealthMsg)
 		m.statusMessage = "Disconnected"
 
 	case tuimsg.ErrorMsg:
@@ -198,6 +292,41 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tuimsg.SuccessMsg:
 		m.statusMessage = msg.Message
 
+	case tuimsg.RoomStatsMsg:
+		m.online = msg.Online
+
+	case tuimsg.ConnectionStateMsg:
+		previous := m.connection.State
+		m.connection = msg
+		switch msg.State {
+		case "reconnecting", "failed":
+			m.online = -1
+			if msg.State == "failed" && msg.Err != nil {
+				m.statusMessage = fmt.Sprintf("Connection failed: %v", msg.Err)
+			}
+		case "connected":
+			if previous == "reconnecting" {
+				m.statusMessage = "Reconnected"
+			}
+		}
+
+	case tuimsg.PluginStateMsg:
+		if msg.State == "closed" {
+			delete(m.pluginStates, msg.Source)
+		} else {
+			m.pluginStates[msg.Source] = msg.State
+		}
+
+	case tuimsg.PluginHealthMsg:
+		if msg.State == "stopped" {
+			delete(m.consumerHealth, msg.ID)
+		} else {
+			m.consumerHealth[msg.ID] = msg
+		}
+
+	case tuimsg.EventMsg:
+		m.statusMessage = fmt.Sprintf("[%s] %s", msg.Event.Level, msg.Event)
+
 	case tuimsg.UpdateServerConfigMsg:
 		// 更新配置
 		m.config.Server.APIAddress = msg.APIAddress
@@ -238,6 +367,16 @@ func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	m.deadLetters, cmd = m.deadLetters.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	m.blockList, cmd = m.blockList.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
@@ -249,9 +388,17 @@ func (m RootModel) View() string {
 	// 连接信息
 	connectionInfo := ""
 	if m.selectedService != nil {
-		connectionInfo = infoStyle.Width(m.width).Render(
-			fmt.Sprintf("Connected: %s/%s", m.selectedService.Platform, m.selectedService.RID),
-		)
+		info := m.renderConnection()
+		if m.online >= 0 {
+			info += fmt.Sprintf(" | Online %d", m.online)
+		}
+		if health := m.renderConsumerHealth(); health != "" {
+			info += " | " + health
+		}
+		if states := m.renderPluginStates(); states != "" {
+			info += " | " + states
+		}
+		connectionInfo = infoStyle.Width(m.width).Render(info)
 	} else {
 		connectionInfo = dimStyle.Width(m.width).Render("Not connected - Press s to select service")
 	}
@@ -263,7 +410,7 @@ func (m RootModel) View() string {
 	status := statusStyle.Width(m.width).Render(m.statusMessage)
 
 	// 帮助栏
-	help := helpStyle.Width(m.width).Render("a:Add | s:Services | c:Config | p:Plugins | r:Refresh | d:Disconnect | q:Quit")
+	help := helpStyle.Width(m.width).Render("a:Add | s:Services | c:Config | p:Plugins | l:Dead letters | b:Block | B:Blocked | r:Refresh | d:Disconnect | q:Quit")
 
 	mainView := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -311,6 +458,30 @@ func (m RootModel) View() string {
 		)
 	}
 
+	if m.blockList.IsVisible() {
+		popupView := m.blockList.View()
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			popupView,
+			lipgloss.WithWhitespaceChars(" "),
+		)
+	}
+
+	if m.deadLetters.IsVisible() {
+		popupView := m.deadLetters.View()
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			popupView,
+			lipgloss.WithWhitespaceChars(" "),
+		)
+	}
+
 	if m.pluginsConfig.IsVisible() {
 		popupView := m.pluginsConfig.View()
 		return lipgloss.Place(
@@ -330,3 +501,73 @@ func (m RootModel) View() string {
 func (m RootModel) GetConfig() *AppConfig {
 	return m.config
 }
+
+// renderConnection 渲染连接状态，重连中显示尝试次数和等待时间
+func (m RootModel) renderConnection() string {
+	service := fmt.Sprintf("%s/%s", m.selectedService.Platform, m.selectedService.RID)
+
+	switch m.connection.State {
+	case "connecting":
+		return "Connecting: " + service
+	case "reconnecting":
+		return fmt.Sprintf("Reconnecting: %s (attempt %d, waiting %s)", service, m.connection.Attempt, m.connection.Delay.Round(100*time.Millisecond))
+	case "failed":
+		return "Connection failed: " + service
+	}
+
+	info := "Connected: " + service
+	if m.connection.Reconnects > 0 {
+		info += fmt.Sprintf(" | Reconnects %d", m.connection.Reconnects)
+	}
+	return info
+}
+
+// renderConsumerHealth 渲染健康的消费者数量，并列出未处于运行状态的消费者
+func (m RootModel) renderConsumerHealth() string {
+	if len(m.consumerHealth) == 0 {
+		return ""
+	}
+
+	ids := make([]string, 0, len(m.consumerHealth))
+	for id := range m.consumerHealth {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	healthy := 0
+	var unhealthy []string
+	for _, id := range ids {
+		health := m.consumerHealth[id]
+		if health.State == "running" {
+			healthy++
+			continue
+		}
+		unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", health.Name, health.State))
+	}
+
+	result := fmt.Sprintf("Consumers %d/%d healthy", healthy, len(ids))
+	if len(unhealthy) > 0 {
+		result += " (" + strings.Join(unhealthy, ", ") + ")"
+	}
+	return result
+}
+
+// renderPluginStates 渲染处于熔断或试探状态的插件
+func (m RootModel) renderPluginStates() string {
+	if len(m.pluginStates) == 0 {
+		return ""
+	}
+
+	sources := make([]string, 0, len(m.pluginStates))
+	for source := range m.pluginStates {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	parts := make([]string, len(sources))
+	for i, source := range sources {
+		parts[i] = fmt.Sprintf("%s: %s", source, m.pluginStates[source])
+	}
+
+	return "Breaker " + strings.Join(parts, ", ")
+}
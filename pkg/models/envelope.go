@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MessageVersion 消息 JSON 信封的当前版本
+const MessageVersion = 1
+
+// envelope 消息的 JSON 信封
+//
+// data 为平台原始数据，formatted 为格式化结果（经过 format_transform 之后才有），
+// meta 为提取出的发送者和时间。UniBarrage 推送的消息没有 v、formatted 和 meta，同样可以解析。
+type envelope struct {
+	Version   int               `json:"v,omitempty"`
+	RID       string            `json:"rid"`
+	Platform  Platform          `json:"platform"`
+	Type      MessageType       `json:"type"`
+	Data      json.RawMessage   `json:"data,omitempty"`
+	Value     *Money            `json:"value,omitempty"` // 币种归一化后的总价值（未格式化的消息）
+	Formatted *FormattedMessage `json:"formatted,omitempty"`
+	Meta      *Metadata         `json:"meta,omitempty"`
+}
+
+// MarshalJSON 序列化为带版本的信封，UnmarshalJSON 可以无损还原
+func (m Message) MarshalJSON() ([]byte, error) {
+	env := envelope{
+		Version:  MessageVersion,
+		RID:      m.RID,
+		Platform: m.Platform,
+		Type:     m.Type,
+		Data:     m.RawData,
+	}
+	if m.Meta != (Metadata{}) {
+		env.Meta = &m.Meta
+	}
+
+	switch data := m.Data.(type) {
+	case *FormattedMessage:
+		env.Formatted = data
+	case nil:
+	default:
+		env.Value = valueField(data)
+		// 只有解析后的数据（如代码中构造的消息）时由其生成原始数据
+		if len(env.Data) == 0 {
+			raw, err := json.Marshal(data)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s data: %w", m.Type, err)
+			}
+			env.Data = raw
+		}
+	}
+
+	return json.Marshal(env)
+}
+
+// UnmarshalJSON 解析消息信封或 UniBarrage 推送的消息
+//
+// 带 formatted 时还原格式化结果，否则从原始数据解析；meta 中非空的字段覆盖从数据提取的结果。
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+	if env.Version > MessageVersion {
+		return fmt.Errorf("unsupported message version %d", env.Version)
+	}
+
+	*m = Message{
+		RID:      env.RID,
+		Platform: env.Platform,
+		Type:     env.Type,
+		RawData:  env.Data,
+	}
+
+	if env.Formatted == nil {
+		if err := m.ParseMessage(); err != nil {
+			return err
+		}
+		if env.Value != nil {
+			setValue(m.Data, env.Value)
+		}
+	} else {
+		formatted := *env.Formatted
+		if formatted.MessageType == "" {
+			formatted.MessageType = env.Type
+		}
+		m.Data = &formatted
+		m.Meta = ExtractMetadata(m.Platform, m.SourceData())
+	}
+
+	if meta := env.Meta; meta != nil {
+		if meta.User != nil {
+			m.Meta.User = meta.User
+		}
+		if !meta.Time.IsZero() {
+			m.Meta.Time = meta.Time
+		}
+		if !meta.Received.IsZero() {
+			m.Meta.Received = meta.Received
+		}
+	}
+	return nil
+}
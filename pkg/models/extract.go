@@ -0,0 +1,237 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rawFields 解码后的原始数据（数字保留为 json.Number）
+type rawFields = map[string]interface{}
+
+// metadataExtractor 平台专用的元数据提取函数，补充通用提取没有得到的字段
+type metadataExtractor func(raw rawFields, meta *Metadata)
+
+// extractors 各平台的元数据提取函数
+var extractors = map[Platform]metadataExtractor{
+	PlatformBilibili: extractBilibili,
+	PlatformDouyin:   extractDouyin,
+	PlatformKuaishou: extractKuaishou,
+	PlatformDouyu:    extractDouyu,
+	PlatformHuya:     extractHuya,
+}
+
+// userIDKeys 原始数据中可能表示用户 ID 的字段，按优先级排列
+var userIDKeys = []string{"uid", "user_id", "userId", "userid", "sec_uid", "open_id"}
+
+// timeKeys 原始数据中可能表示事件时间的字段，按优先级排列
+var timeKeys = []string{"timestamp", "send_time", "sendTime", "create_time", "createTime", "start_time", "time", "ts"}
+
+// ExtractMetadata 从消息数据及其原始数据中提取发送者和事件时间
+//
+// 先按常见字段名通用提取（顶层和 user 对象中的用户 ID、时间戳），
+// 再由平台专用的提取函数补充等级、粉丝勋章等字段。
+func ExtractMetadata(platform Platform, data MessageData) Metadata {
+	var meta Metadata
+
+	var raw json.RawMessage
+	switch d := data.(type) {
+	case *ChatData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *GiftData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *LikeData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *EnterRoomData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *SubscribeData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *SuperChatData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *GuardData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *ShareData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *FollowData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *UnknownData:
+		meta.User, raw = &User{Name: d.Name, Avatar: d.Avatar}, d.Raw
+	case *EndLiveData:
+		raw = d.Raw
+	case *RoomStatsData:
+		raw = d.Raw
+	case *FormattedMessage:
+		if d.User != nil {
+			user := *d.User
+			meta.User = &user
+		}
+		meta.Time = d.Timestamp
+		return meta
+	}
+	if meta.User != nil && meta.User.Name == "" {
+		meta.User = nil
+	}
+
+	fields := decodeRaw(raw)
+	if fields == nil {
+		return meta
+	}
+
+	if meta.User != nil {
+		meta.User.ID = lookupString(fields, userIDKeys)
+		if user, ok := fields["user"].(map[string]interface{}); ok && meta.User.ID == "" {
+			meta.User.ID = lookupString(user, append([]string{"id"}, userIDKeys...))
+		}
+	}
+	meta.Time = lookupTime(fields, timeKeys)
+
+	if extract, ok := extractors[platform]; ok {
+		extract(fields, &meta)
+	}
+	return meta
+}
+
+// decodeRaw 解码原始数据，不是 JSON 对象时返回 nil
+func decodeRaw(raw json.RawMessage) rawFields {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// path 按键或数组下标依次取值，不存在时返回 nil
+func path(value interface{}, keys ...interface{}) interface{} {
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[k]
+		case int:
+			array, ok := value.([]interface{})
+			if !ok || k >= len(array) {
+				return nil
+			}
+			value = array[k]
+		}
+	}
+	return value
+}
+
+// object 返回对象字段，不存在时返回 nil
+func object(value interface{}, keys ...interface{}) map[string]interface{} {
+	m, _ := path(value, keys...).(map[string]interface{})
+	return m
+}
+
+// toString 把字符串或数字转换为字符串，空字符串和 0 视为没有
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if v != "0" {
+			return v
+		}
+	case json.Number:
+		if v.String() != "0" {
+			return v.String()
+		}
+	case float64:
+		if v != 0 {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// toInt 把数字或数字字符串转换为整数
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return int(f)
+		}
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+// toTime 把 Unix 时间戳（秒或毫秒）转换为时间，无法识别时返回零值
+func toTime(value interface{}) time.Time {
+	var n int64
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}
+		}
+		n = int64(f)
+	case float64:
+		n = int64(v)
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+		n = parsed
+	default:
+		return time.Time{}
+	}
+
+	switch {
+	case n >= 1e17: // 纳秒
+		return time.Unix(0, n)
+	case n >= 1e14: // 微秒
+		return time.UnixMicro(n)
+	case n >= 1e11: // 毫秒
+		return time.UnixMilli(n)
+	case n >= 1e9: // 秒
+		return time.Unix(n, 0)
+	}
+	return time.Time{}
+}
+
+// lookupString 返回第一个非空的字段
+func lookupString(fields map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if s := toString(fields[key]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// lookupTime 返回第一个可识别的时间字段
+func lookupTime(fields map[string]interface{}, keys []string) time.Time {
+	for _, key := range keys {
+		if t := toTime(fields[key]); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// setMedal 设置粉丝勋章，名称为空或等级为 0 时忽略
+func setMedal(user *User, name string, level int) {
+	if user != nil && name != "" && level > 0 {
+		user.Medal = &Medal{Name: name, Level: level}
+	}
+}
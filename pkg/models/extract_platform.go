@@ -0,0 +1,120 @@
+package models
+
+// extractBilibili B 站：弹幕为 info 数组，其余事件为 data 对象
+//
+// info[0][4] 发送时间（毫秒），info[2][0] UID，info[3] 粉丝勋章 [等级, 名称, ...]，
+// info[4][0] 用户等级，info[7] 大航海等级；data 中为 uid、timestamp、medal_info 等字段。
+func extractBilibili(raw rawFields, meta *Metadata) {
+	if info, ok := raw["info"].([]interface{}); ok {
+		if meta.Time.IsZero() {
+			meta.Time = toTime(path(info, 0, 4))
+		}
+		if user := meta.User; user != nil {
+			if user.ID == "" {
+				user.ID = toString(path(info, 2, 0))
+			}
+			setMedal(user, toString(path(info, 3, 1)), toInt(path(info, 3, 0)))
+			user.Level = toInt(path(info, 4, 0))
+			user.GuardLevel = toInt(path(info, 7))
+		}
+		return
+	}
+
+	data := object(raw, "data")
+	if data == nil {
+		data = raw
+	}
+	if meta.Time.IsZero() {
+		meta.Time = lookupTime(data, timeKeys)
+	}
+
+	user := meta.User
+	if user == nil {
+		return
+	}
+	if user.ID == "" {
+		user.ID = lookupString(data, userIDKeys)
+	}
+
+	medal := object(data, "medal_info")
+	if medal == nil {
+		medal = object(data, "fans_medal")
+	}
+	if medal != nil {
+		setMedal(user, toString(medal["medal_name"]), toInt(medal["medal_level"]))
+		user.GuardLevel = toInt(medal["guard_level"])
+	}
+	if level := toInt(data["guard_level"]); level > 0 {
+		user.GuardLevel = level
+	}
+	if info := object(data, "user_info"); info != nil {
+		user.Level = toInt(info["user_level"])
+		if level := toInt(info["guard_level"]); level > 0 {
+			user.GuardLevel = level
+		}
+	}
+}
+
+// extractDouyin 抖音：common.createTime 为事件时间（毫秒），user 中为 ID、财富等级和粉丝团
+func extractDouyin(raw rawFields, meta *Metadata) {
+	if meta.Time.IsZero() {
+		meta.Time = toTime(path(raw, "common", "createTime"))
+	}
+
+	user := meta.User
+	u := object(raw, "user")
+	if user == nil || u == nil {
+		return
+	}
+	if user.ID == "" {
+		user.ID = lookupString(u, []string{"idStr", "shortId", "secUid"})
+	}
+	user.Level = toInt(path(u, "payGrade", "level"))
+	setMedal(user, toString(path(u, "fansClub", "data", "clubName")), toInt(path(u, "fansClub", "data", "level")))
+}
+
+// extractKuaishou 快手：user.principalId 为用户 ID，sendTimeMs 为事件时间
+func extractKuaishou(raw rawFields, meta *Metadata) {
+	if meta.Time.IsZero() {
+		meta.Time = toTime(raw["sendTimeMs"])
+	}
+	if user := meta.User; user != nil && user.ID == "" {
+		user.ID = toString(path(raw, "user", "principalId"))
+	}
+}
+
+// extractDouyu 斗鱼：level 用户等级，bnn/bl 粉丝牌名称和等级，nl 贵族等级，cst 发送时间（毫秒）
+func extractDouyu(raw rawFields, meta *Metadata) {
+	if meta.Time.IsZero() {
+		meta.Time = toTime(raw["cst"])
+	}
+
+	user := meta.User
+	if user == nil {
+		return
+	}
+	user.Level = toInt(raw["level"])
+	setMedal(user, toString(raw["bnn"]), toInt(raw["bl"]))
+	user.GuardLevel = toInt(raw["nl"])
+}
+
+// extractHuya 虎牙：lUid 用户 ID，tBadgeInfo 粉丝徽章，iNobleLevel 贵族等级
+func extractHuya(raw rawFields, meta *Metadata) {
+	user := meta.User
+	if user == nil {
+		return
+	}
+
+	sender := object(raw, "tUserInfo")
+	if sender == nil {
+		sender = raw
+	}
+	if user.ID == "" {
+		user.ID = lookupString(sender, []string{"lUid", "lUserId"})
+	}
+	user.Level = toInt(sender["iLevel"])
+	user.GuardLevel = toInt(sender["iNobleLevel"])
+	if badge := object(raw, "tBadgeInfo"); badge != nil {
+		setMedal(user, toString(badge["sBadgeName"]), toInt(badge["iBadgeLevel"]))
+	}
+}
@@ -12,10 +12,17 @@ type FormattedMessage struct {
 	Timestamp time.Time `json:"timestamp"` // 时间戳
 
 	// 扩展字段
-	Type string `json:"type"` // 消息类型：chat, superchat, gift, subscribe, like, enterroom, endlive
+	Type  string `json:"type"`            // 消息类型：chat, superchat, gift, subscribe, like, enterroom, endlive, guard, share, follow, roomstats，未知类型为原始类型名称的小写形式
+	Value *Money `json:"value,omitempty"` // 总价值（礼物、订阅、SuperChat）
+
+	// User 发送者的 ID、等级和粉丝勋章，没有发送者时为 nil
+	User *User `json:"user,omitempty"`
+
+	// Segments 聊天内容的结构化分段（文本、表情、提及），拼接后与 Content 对应
+	Segments []Segment `json:"segments,omitempty"`
 
 	// 原始消息类型
-	MessageType MessageType `json:"-"`
+	MessageType MessageType `json:"messageType,omitempty"`
 }
 
 // GetType 实现 MessageData 接口
@@ -3,15 +3,19 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Message WebSocket 消息结构
+//
+// JSON 序列化为带版本的信封，见 MarshalJSON。
 type Message struct {
 	RID      string          `json:"rid"`      // 房间号
 	Platform Platform        `json:"platform"` // 平台
 	Type     MessageType     `json:"type"`     // 消息类型
-	Data     MessageData     `json:"-"`        // 解析后的数据
+	Data     MessageData     `json:"-"`        // 解析后的数据，格式化结果序列化为 formatted
 	RawData  json.RawMessage `json:"data"`     // 原始 JSON 数据
+	Meta     Metadata        `json:"-"`        // 发送者和事件时间，由 ParseMessage 提取，序列化为 meta
 }
 
 // MessageData 消息数据接口
@@ -39,6 +43,8 @@ type GiftData struct {
 	Price    float64         `json:"price"`    // 礼物单价
 	GiftIcon string          `json:"giftIcon"` // 礼物图标
 	Raw      json.RawMessage `json:"raw"`      // 原始数据
+
+	Value *Money `json:"value,omitempty"` // 归一化后的总价值，由币种归一化阶段填充
 }
 
 func (g *GiftData) GetType() MessageType { return TypeGift }
@@ -70,6 +76,8 @@ type SubscribeData struct {
 	Num    int             `json:"num"`    // 订阅次数
 	Price  float64         `json:"price"`  // 订阅单价
 	Raw    json.RawMessage `json:"raw"`    // 原始数据
+
+	Value *Money `json:"value,omitempty"` // 归一化后的总价值，由币种归一化阶段填充
 }
 
 func (s *SubscribeData) GetType() MessageType { return TypeSubscribe }
@@ -81,6 +89,8 @@ type SuperChatData struct {
 	Content string          `json:"content"` // 超级聊天内容
 	Price   float64         `json:"price"`   // 金额
 	Raw     json.RawMessage `json:"raw"`     // 原始数据
+
+	Value *Money `json:"value,omitempty"` // 归一化后的金额，由币种归一化阶段填充
 }
 
 func (s *SuperChatData) GetType() MessageType { return TypeSuperChat }
@@ -92,10 +102,64 @@ type EndLiveData struct {
 
 func (e *EndLiveData) GetType() MessageType { return TypeEndLive }
 
+// GuardData 开通会员消息（如 B 站大航海）
+type GuardData struct {
+	Name   string          `json:"name"`   // 开通者名称
+	Avatar string          `json:"avatar"` // 开通者头像
+	Level  int             `json:"level"`  // 会员等级（B 站 1 总督 2 提督 3 舰长）
+	Num    int             `json:"num"`    // 开通月数
+	Price  float64         `json:"price"`  // 每月单价
+	Raw    json.RawMessage `json:"raw"`    // 原始数据
+
+	Value *Money `json:"value,omitempty"` // 归一化后的总价值，由币种归一化阶段填充
+}
+
+func (g *GuardData) GetType() MessageType { return TypeGuard }
+
+// ShareData 分享直播间消息
+type ShareData struct {
+	Name   string          `json:"name"`   // 分享者名称
+	Avatar string          `json:"avatar"` // 分享者头像
+	Raw    json.RawMessage `json:"raw"`    // 原始数据
+}
+
+func (s *ShareData) GetType() MessageType { return TypeShare }
+
+// FollowData 关注主播消息
+type FollowData struct {
+	Name   string          `json:"name"`   // 关注者名称
+	Avatar string          `json:"avatar"` // 关注者头像
+	Raw    json.RawMessage `json:"raw"`    // 原始数据
+}
+
+func (f *FollowData) GetType() MessageType { return TypeFollow }
+
+// RoomStatsData 直播间统计消息
+type RoomStatsData struct {
+	Online int64           `json:"online"` // 在线人数
+	Raw    json.RawMessage `json:"raw"`    // 原始数据
+}
+
+func (r *RoomStatsData) GetType() MessageType { return TypeRoomStats }
+
+// UnknownData 未知类型的消息，保留类型名称和原始数据，照常经过管道
+//
+// 数据中有 name / avatar 字段时一并解析，便于显示和按用户过滤。
+type UnknownData struct {
+	Type   MessageType     `json:"-"`      // 原始类型名称
+	Name   string          `json:"name"`   // 发送者名称（可能为空）
+	Avatar string          `json:"avatar"` // 发送者头像（可能为空）
+	Raw    json.RawMessage `json:"raw"`    // 原始数据
+}
+
+func (u *UnknownData) GetType() MessageType { return u.Type }
+
 // ParseMessage 解析消息数据
+//
+// 未知类型解析为 UnknownData，不返回错误；已知类型的数据格式错误时返回错误。
 func (m *Message) ParseMessage() error {
-	if !m.Type.IsValid() {
-		return fmt.Errorf("invalid message type: %s", m.Type)
+	if m.Type == "" {
+		return fmt.Errorf("missing message type")
 	}
 
 	switch m.Type {
@@ -147,23 +211,73 @@ func (m *Message) ParseMessage() error {
 			return fmt.Errorf("parse end live data: %w", err)
 		}
 		m.Data = &data
+
+	case TypeGuard:
+		var data GuardData
+		if err := json.Unmarshal(m.RawData, &data); err != nil {
+			return fmt.Errorf("parse guard data: %w", err)
+		}
+		m.Data = &data
+
+	case TypeShare:
+		var data ShareData
+		if err := json.Unmarshal(m.RawData, &data); err != nil {
+			return fmt.Errorf("parse share data: %w", err)
+		}
+		m.Data = &data
+
+	case TypeFollow:
+		var data FollowData
+		if err := json.Unmarshal(m.RawData, &data); err != nil {
+			return fmt.Errorf("parse follow data: %w", err)
+		}
+		m.Data = &data
+
+	case TypeRoomStats:
+		var data RoomStatsData
+		if err := json.Unmarshal(m.RawData, &data); err != nil {
+			return fmt.Errorf("parse room stats data: %w", err)
+		}
+		m.Data = &data
+
+	default:
+		// 数据不是 JSON 对象时只保留原始数据
+		data := UnknownData{Type: m.Type}
+		if err := json.Unmarshal(m.RawData, &data); err != nil {
+			data = UnknownData{Type: m.Type}
+		}
+		if len(data.Raw) == 0 {
+			data.Raw = m.RawData
+		}
+		m.Data = &data
+	}
+
+	received := m.Meta.Received
+	if received.IsZero() {
+		received = time.Now()
 	}
+	m.Meta = ExtractMetadata(m.Platform, m.Data)
+	m.Meta.Received = received
 
 	return nil
 }
 
-// UnmarshalJSON 自定义 JSON 反序列化
-func (m *Message) UnmarshalJSON(data []byte) error {
-	type Alias Message
-	aux := &struct {
-		*Alias
-	}{
-		Alias: (*Alias)(m),
+// SourceData 返回平台数据
+//
+// Data 为解析后的数据时直接返回（包括币种归一化填充的 Value）；已被格式化结果替换时
+// 从 RawData 重新解析，并带上格式化结果中的 Value。解析失败返回 Data。
+func (m *Message) SourceData() MessageData {
+	formatted, ok := m.Data.(*FormattedMessage)
+	if !ok {
+		return m.Data
 	}
 
-	if err := json.Unmarshal(data, aux); err != nil {
-		return err
+	raw := *m
+	if err := raw.ParseMessage(); err != nil {
+		return m.Data
 	}
-
-	return m.ParseMessage()
+	if formatted.Value != nil {
+		setValue(raw.Data, formatted.Value)
+	}
+	return raw.Data
 }
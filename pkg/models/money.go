@@ -0,0 +1,76 @@
+package models
+
+import "fmt"
+
+// CurrencyCNY 人民币，未经币种归一化的价格按此解释
+const CurrencyCNY = "CNY"
+
+// Money 带币种的金额
+type Money struct {
+	Amount   float64 `json:"amount"`   // 金额
+	Currency string  `json:"currency"` // ISO 4217 币种代码（如 CNY）
+}
+
+// String 返回 "金额 币种" 形式的描述
+func (m Money) String() string {
+	return fmt.Sprintf("%.2f %s", m.Amount, m.Currency)
+}
+
+// ValueOf 返回消息的总价值
+//
+// 经过币种归一化的消息返回其 Value；否则按原始价格计算（礼物、订阅和开通会员为单价 × 数量，
+// SuperChat 为金额），币种视为 CNY。没有价格的消息返回 false。
+func ValueOf(data MessageData) (Money, bool) {
+	switch d := data.(type) {
+	case *GiftData:
+		if d.Value != nil {
+			return *d.Value, true
+		}
+		return Money{Amount: d.Price * float64(d.Num), Currency: CurrencyCNY}, true
+	case *SubscribeData:
+		if d.Value != nil {
+			return *d.Value, true
+		}
+		return Money{Amount: d.Price * float64(d.Num), Currency: CurrencyCNY}, true
+	case *GuardData:
+		if d.Value != nil {
+			return *d.Value, true
+		}
+		return Money{Amount: d.Price * float64(d.Num), Currency: CurrencyCNY}, true
+	case *SuperChatData:
+		if d.Value != nil {
+			return *d.Value, true
+		}
+		return Money{Amount: d.Price, Currency: CurrencyCNY}, true
+	}
+	return Money{}, false
+}
+
+// setValue 为带价格的数据设置归一化后的总价值，其他数据忽略
+func setValue(data MessageData, value *Money) {
+	switch d := data.(type) {
+	case *GiftData:
+		d.Value = value
+	case *SubscribeData:
+		d.Value = value
+	case *GuardData:
+		d.Value = value
+	case *SuperChatData:
+		d.Value = value
+	}
+}
+
+// valueField 返回数据中归一化后的总价值，未归一化或没有价格时返回 nil
+func valueField(data MessageData) *Money {
+	switch d := data.(type) {
+	case *GiftData:
+		return d.Value
+	case *SubscribeData:
+		return d.Value
+	case *GuardData:
+		return d.Value
+	case *SuperChatData:
+		return d.Value
+	}
+	return nil
+}
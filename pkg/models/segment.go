@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// SegmentType 内容分段类型
+type SegmentType string
+
+const (
+	SegmentText     SegmentType = "text"     // 文本
+	SegmentEmoticon SegmentType = "emoticon" // 表情
+	SegmentMention  SegmentType = "mention"  // 提及用户
+)
+
+// Segment 结构化内容分段
+type Segment struct {
+	Type SegmentType `json:"type"`
+	Text string      `json:"text,omitempty"` // 文本；表情为表情名（如 [doge]，可能为空）；提及为用户名（不含 @）
+	URL  string      `json:"url,omitempty"`  // 表情图片 URL
+}
+
+// PlainText 把分段拼接为纯文本，表情用表情名代替，没有表情名时使用 placeholder
+func PlainText(segments []Segment, placeholder string) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		switch seg.Type {
+		case SegmentEmoticon:
+			if seg.Text != "" {
+				b.WriteString(seg.Text)
+			} else {
+				b.WriteString(placeholder)
+			}
+		case SegmentMention:
+			b.WriteString("@" + seg.Text)
+		default:
+			b.WriteString(seg.Text)
+		}
+	}
+	return b.String()
+}
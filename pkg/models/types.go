@@ -11,18 +11,47 @@ const (
 	TypeSubscribe MessageType = "Subscribe"
 	TypeSuperChat MessageType = "SuperChat"
 	TypeEndLive   MessageType = "EndLive"
+	TypeGuard     MessageType = "Guard"     // 开通会员（如 B 站大航海）
+	TypeShare     MessageType = "Share"     // 分享直播间
+	TypeFollow    MessageType = "Follow"    // 关注主播
+	TypeRoomStats MessageType = "RoomStats" // 直播间统计（在线人数）
+
+	// TypeUnknown 未知类型的占位名称，用于配置（如 unknown_template）；
+	// 未知类型的消息保留其原始类型名称，数据为 UnknownData
+	TypeUnknown MessageType = "Unknown"
 )
 
+// Types 已知的消息类型
+var Types = []MessageType{
+	TypeChat, TypeGift, TypeLike, TypeEnterRoom, TypeSubscribe, TypeSuperChat, TypeEndLive,
+	TypeGuard, TypeShare, TypeFollow, TypeRoomStats,
+}
+
+// TypeNames 返回已知消息类型的名称，用于配置选项
+func TypeNames() []string {
+	names := make([]string, len(Types))
+	for i, t := range Types {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// TypeOptions 返回配置中可选的消息类型：已知类型加上表示所有未知类型的 Unknown
+func TypeOptions() []string {
+	return append(TypeNames(), string(TypeUnknown))
+}
+
 // String 返回消息类型名称
 func (t MessageType) String() string {
 	return string(t)
 }
 
-// IsValid 检查消息类型是否有效
+// IsValid 检查是否为已知的消息类型，未知类型的消息解析为 UnknownData
 func (t MessageType) IsValid() bool {
 	switch t {
 	case TypeChat, TypeGift, TypeLike, TypeEnterRoom,
-		TypeSubscribe, TypeSuperChat, TypeEndLive:
+		TypeSubscribe, TypeSuperChat, TypeEndLive,
+		TypeGuard, TypeShare, TypeFollow, TypeRoomStats:
 		return true
 	}
 	return false
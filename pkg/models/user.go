@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// User 消息发送者
+type User struct {
+	ID         string `json:"id,omitempty"`         // 平台用户 ID，原始数据中没有时为空
+	Name       string `json:"name"`                 // 昵称
+	Avatar     string `json:"avatar,omitempty"`     // 头像 URL
+	Level      int    `json:"level,omitempty"`      // 用户等级（如 B 站 UL、抖音财富等级）
+	Medal      *Medal `json:"medal,omitempty"`      // 佩戴的粉丝勋章
+	GuardLevel int    `json:"guardLevel,omitempty"` // 会员等级（B 站大航海 1 总督 2 提督 3 舰长，斗鱼、虎牙为贵族等级），0 表示无
+}
+
+// Medal 粉丝勋章
+type Medal struct {
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+}
+
+// Metadata 从平台原始数据中提取的消息元数据
+type Metadata struct {
+	User     *User     `json:"user,omitempty"`    // 发送者，没有发送者的消息（如直播结束）为 nil
+	Time     time.Time `json:"time,omitzero"`     // 平台事件时间，原始数据中没有时为零值
+	Received time.Time `json:"received,omitzero"` // 接收（解析）时间
+}
+
+// Time 返回消息的事件时间，平台没有提供时返回接收时间
+func (m *Message) Time() time.Time {
+	switch {
+	case !m.Meta.Time.IsZero():
+		return m.Meta.Time
+	case !m.Meta.Received.IsZero():
+		return m.Meta.Received
+	}
+	return time.Now()
+}
+
+// Sender 返回消息发送者，没有发送者的消息返回 nil
+//
+// Meta 未填充时（如外部插件构造的消息）从原始数据重新提取。
+func (m *Message) Sender() *User {
+	if m.Meta.User != nil {
+		return m.Meta.User
+	}
+	if formatted, ok := m.Data.(*FormattedMessage); ok && formatted.User != nil {
+		return formatted.User
+	}
+	if user := ExtractMetadata(m.Platform, m.SourceData()).User; user != nil {
+		return user
+	}
+	if formatted, ok := m.Data.(*FormattedMessage); ok && formatted.UserName != "" {
+		return &User{Name: formatted.UserName, Avatar: formatted.Avatar}
+	}
+	return nil
+}
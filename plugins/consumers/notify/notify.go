@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/gen2brain/beeep"
+	"github.com/xifan2333/dmnotifier/internal/event"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
@@ -44,8 +45,11 @@ func (c *Consumer) Init(ctx context.Context, config map[string]interface{}) erro
 
 	avatarCache, err := NewAvatarCache(cacheDir)
 	if err != nil {
-
 		// 继续运行，只是不缓存头像
+		e := event.PluginError(c.Name(), err)
+		e.Level = event.LevelWarn
+		e.Message = "avatar cache disabled"
+		event.Publish(e)
 	} else {
 		c.avatarCache = avatarCache
 
@@ -69,6 +73,11 @@ func (c *Consumer) Consume(ctx context.Context, msg *models.Message) error {
 		return nil
 	}
 
+	// 直播间统计是周期性的状态数据，不发通知
+	if formatted.MessageType == models.TypeRoomStats {
+		return nil
+	}
+
 	title := fmt.Sprintf("%s | %s", formatted.Platform, formatted.UserName)
 	message := formatted.Content
 
@@ -83,11 +92,9 @@ func (c *Consumer) sendNotification(title, message, iconURL string) error {
 		iconPath = c.avatarCache.Get(iconURL)
 	}
 
-	// 使用 beeep 发送跨平台通知
-	err := beeep.Notify(title, message, iconPath)
-	if err != nil {
-
-		return err
+	// 使用 beeep 发送跨平台通知（通知服务暂不可用时可重试）
+	if err := beeep.Notify(title, message, iconPath); err != nil {
+		return plugin.Retryable(fmt.Errorf("send notification: %w", err))
 	}
 
 	return nil
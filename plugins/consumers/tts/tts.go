@@ -8,6 +8,8 @@ import (
 	"runtime"
 
 	"github.com/lib-x/edgetts"
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/i18n"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
@@ -28,8 +30,8 @@ type Consumer struct {
 	cancel context.CancelFunc
 
 	// 配置
-	voice    string // 音色
-	language string // 语言
+	voice  string      // 音色
+	locale i18n.Locale // 播报句式的语言环境
 }
 
 // New 创建 TTS 消费者
@@ -37,7 +39,7 @@ func New() plugin.Plugin {
 	return &Consumer{
 		BasePlugin: plugin.NewBasePlugin("tts", plugin.TypeConsumer),
 		voice:      "zh-CN-XiaoxiaoNeural", // 默认音色
-		language:   "zh-CN",                // 默认语言
+		locale:     i18n.Default,           // 默认语言
 	}
 }
 
@@ -55,8 +57,11 @@ func (c *Consumer) Init(ctx context.Context, config map[string]interface{}) erro
 
 	}
 	if language, ok := config["language"].(string); ok && language != "" {
-		c.language = language
-
+		locale, err := i18n.Parse(language)
+		if err != nil {
+			return err
+		}
+		c.locale = locale
 	}
 
 	// 读取队列长度配置
@@ -83,33 +88,31 @@ func (c *Consumer) Init(ctx context.Context, config map[string]interface{}) erro
 	return nil
 }
 
-// Consume 消费消息 - 异步生成音频
+// Consume 消费消息：按消息顺序生成音频并加入播放队列
+//
+// 生成失败（网络错误等）返回可重试错误，由管道重试或写入死信；
+// 超时后才生成完的音频不再播放，避免与重试重复。
 func (c *Consumer) Consume(ctx context.Context, msg *models.Message) error {
-
 	text := c.formatMessage(msg)
 	if text == "" {
 		return nil
 	}
 
-	// 异步生成音频
-	go func() {
-		audioData, err := c.generateAudio(text)
-		if err != nil {
-
-			return
-		}
-
-		// 添加到播放队列（非阻塞）
-		select {
-		case c.queue <- &audioItem{text: text, audioData: audioData}:
-
-		case <-c.ctx.Done():
-
-			return
-		default:
+	audioData, err := c.generateAudio(text)
+	if err != nil {
+		return plugin.Retryable(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		}
-	}()
+	// 添加到播放队列（非阻塞）
+	select {
+	case c.queue <- &audioItem{text: text, audioData: audioData}:
+	case <-c.ctx.Done():
+	default:
+		event.Publish(event.MessageDropped(c.Name(), "playback queue full"))
+	}
 
 	return nil
 }
@@ -119,8 +122,8 @@ func (c *Consumer) playLoop() {
 	for {
 		select {
 		case item := <-c.queue:
-			if err := c.speakDirect(item.audioData); err != nil {
-
+			if err := c.speakDirect(item.audioData); err != nil && c.ctx.Err() == nil {
+				event.Publish(event.PluginError(c.Name(), err))
 			}
 		case <-c.ctx.Done():
 			return
@@ -141,6 +144,11 @@ func (c *Consumer) Stop(ctx context.Context) error {
 	return nil
 }
 
+// HealthCheck 检查播放器是否仍然可用（如被卸载）
+func (c *Consumer) HealthCheck(ctx context.Context) error {
+	return c.checkTTS()
+}
+
 // formatMessage 格式化消息为语音文本
 func (c *Consumer) formatMessage(msg *models.Message) string {
 	// 只处理格式化后的消息
@@ -151,11 +159,11 @@ func (c *Consumer) formatMessage(msg *models.Message) string {
 
 	switch formatted.Type {
 	case "chat":
-		return fmt.Sprintf("%s说：%s", formatted.UserName, formatted.Content)
+		return c.locale.Sprintf("tts.chat", formatted.UserName, formatted.Content)
 
-	case "superchat", "gift", "subscribe", "like", "enterroom":
+	case "superchat", "gift", "subscribe", "like", "enterroom", "guard", "share", "follow":
 		// Content 已经是组装好的描述文本
-		return fmt.Sprintf("%s%s", formatted.UserName, formatted.Content)
+		return c.locale.Sprintf("tts.event", formatted.UserName, formatted.Content)
 
 	case "endlive":
 		// 直播结束没有用户名
@@ -166,7 +174,7 @@ func (c *Consumer) formatMessage(msg *models.Message) string {
 	}
 }
 
-// generateAudio 生成音频数据（每次创建新的 Speech 实例，支持并发）
+// generateAudio 生成音频数据（每次创建新的 Speech 实例）
 func (c *Consumer) generateAudio(text string) ([]byte, error) {
 	// 创建 Speech 实例
 	speech, err := edgetts.NewSpeech(
@@ -295,10 +303,12 @@ func init() {
 				Desc:    "TTS 音色（如：zh-CN-XiaoxiaoNeural, zh-CN-YunxiNeural）",
 			},
 			{
-				Name:    "language",
-				Type:    plugin.FieldTypeString,
-				Default: "zh-CN",
-				Desc:    "语言代码（如：zh-CN, en-US）",
+				Name:     "language",
+				Type:     plugin.FieldTypeEnum,
+				Default:  string(i18n.Default),
+				Desc:     "播报句式的语言（音色需单独选择对应语言）",
+				Options:  i18n.Names(),
+				Validate: i18n.Validate,
 			},
 			{
 				Name:    "queue_size",
@@ -3,9 +3,12 @@ package tui
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
+	tuimsg "github.com/xifan2333/dmnotifier/internal/common"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/internal/tui/components"
 	"github.com/xifan2333/dmnotifier/pkg/models"
@@ -96,11 +99,23 @@ func (c *Consumer) Consume(ctx context.Context, msg *models.Message) error {
 		return nil
 	}
 
+	// 直播间统计显示在标题栏，不作为消息行
+	if stats, ok := msg.SourceData().(*models.RoomStatsData); ok {
+		c.program.Send(tuimsg.RoomStatsMsg{Online: stats.Online})
+		return nil
+	}
+
 	// 格式化消息内容
 	content := c.formatMessage(formatted)
 
-	// 异步发送到 TUI，避免阻塞
-	go c.program.Send(components.AddMessageMsg{Content: content})
+	// 附带发送者，供消息面板屏蔽用户
+	addMsg := components.AddMessageMsg{Content: content}
+	if author, ok := blocklist.FromMessage(msg); ok {
+		addMsg.Author = &author
+	}
+
+	// 在消费者自己的工作协程中同步发送，保持消息顺序
+	c.program.Send(addMsg)
 
 	return nil
 }
@@ -131,10 +146,36 @@ func (c *Consumer) formatMessage(msg *models.FormattedMessage) string {
 		timeStyle.Render(fmt.Sprintf("[%s]", timeStr)),
 		platformTag,
 		msg.UserName,
-		msg.Content,
+		renderContent(msg),
 	)
 }
 
+// emotePlaceholder 终端无法显示表情图片，没有表情名时用占位文本代替
+const emotePlaceholder = "[emote]"
+
+// renderContent 渲染消息内容，有结构化分段时表情和提及以不同颜色显示
+func renderContent(msg *models.FormattedMessage) string {
+	if len(msg.Segments) == 0 {
+		return msg.Content
+	}
+
+	emoteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#e8a33d"))
+	mentionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fafff"))
+
+	var b strings.Builder
+	for _, seg := range msg.Segments {
+		switch seg.Type {
+		case models.SegmentEmoticon:
+			b.WriteString(emoteStyle.Render(models.PlainText([]models.Segment{seg}, emotePlaceholder)))
+		case models.SegmentMention:
+			b.WriteString(mentionStyle.Render("@" + seg.Text))
+		default:
+			b.WriteString(seg.Text)
+		}
+	}
+	return b.String()
+}
+
 // Stop 停止插件
 func (c *Consumer) Stop(ctx context.Context) error {
 	// 取消上下文，阻止新消息发送到 TUI
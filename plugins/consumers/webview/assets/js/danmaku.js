@@ -62,6 +62,11 @@ class LiveChatManager {
     }
 
     addMessage(message) {
+        // 直播间统计不是弹幕，不显示
+        if (message.type === 'roomstats') {
+            return;
+        }
+
         console.log('Adding message:', message);
 
         // 创建消息元素并添加到容器
@@ -184,9 +189,7 @@ class LiveChatManager {
         const messageSpan = document.createElement('span');
         messageSpan.id = 'message';
 
-        const messageText = document.createElement('span');
-        messageText.textContent = message.content;
-        messageSpan.appendChild(messageText);
+        messageSpan.appendChild(this.createContentElement(message));
 
         // 价格标签（如果有）
         if (message.price && message.price > 0) {
@@ -281,7 +284,7 @@ class LiveChatManager {
         // 消息内容
         const messageSpan = document.createElement('span');
         messageSpan.id = 'message';
-        messageSpan.textContent = message.content;
+        messageSpan.appendChild(this.createContentElement(message));
 
         content.appendChild(header);
         content.appendChild(messageSpan);
@@ -292,6 +295,40 @@ class LiveChatManager {
         return renderer;
     }
 
+    createContentElement(message) {
+        const messageText = document.createElement('span');
+        if (!Array.isArray(message.segments) || message.segments.length === 0) {
+            messageText.textContent = message.content;
+            return messageText;
+        }
+
+        // 结构化分段：文本、表情图片（经代理加载）、提及
+        for (const segment of message.segments) {
+            if (segment.type === 'emoticon' && segment.url) {
+                const emote = document.createElement('img');
+                emote.className = 'emoticon';
+                emote.src = segment.url.startsWith('http')
+                    ? `/proxy/image?url=${encodeURIComponent(segment.url)}`
+                    : segment.url;
+                emote.alt = segment.text || '[emote]';
+                emote.title = segment.text || '';
+                emote.onerror = function() {
+                    // 表情加载失败时显示表情名
+                    this.replaceWith(document.createTextNode(this.alt));
+                };
+                messageText.appendChild(emote);
+            } else if (segment.type === 'mention') {
+                const mention = document.createElement('span');
+                mention.className = 'mention';
+                mention.textContent = `@${segment.text}`;
+                messageText.appendChild(mention);
+            } else {
+                messageText.appendChild(document.createTextNode(segment.text || ''));
+            }
+        }
+        return messageText;
+    }
+
     getPriceLevel(price) {
         if (price >= 500) return '7';
         if (price >= 200) return '6';
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/gorilla/websocket"
+	"github.com/xifan2333/dmnotifier/internal/event"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
@@ -27,6 +28,10 @@ type Consumer struct {
 	port     int
 	autoPort bool // 自动寻找可用端口
 
+	// HTTP 服务异常退出的原因
+	serveErr   error
+	serveErrMu sync.Mutex
+
 	// WebSocket 客户端管理
 	clients   map[*websocket.Conn]bool
 	clientsMu sync.RWMutex
@@ -119,7 +124,7 @@ func (c *Consumer) Start(ctx context.Context) error {
 	}
 
 	if c.port != startPort {
-
+		event.Publish(event.PluginStatus(c.Name(), fmt.Sprintf("port %d in use, listening on %d", startPort, c.port)))
 	}
 
 	// 创建 HTTP 服务器
@@ -137,7 +142,11 @@ func (c *Consumer) Start(ctx context.Context) error {
 		defer c.wg.Done()
 
 		if err := c.server.Serve(listener); err != nil && err != http.ErrServerClosed {
-
+			err = fmt.Errorf("serve: %w", err)
+			c.serveErrMu.Lock()
+			c.serveErr = err
+			c.serveErrMu.Unlock()
+			event.Publish(event.PluginError(c.Name(), err))
 		}
 	}()
 
@@ -159,9 +168,10 @@ func (c *Consumer) Stop(ctx context.Context) error {
 	close(c.broadcast)
 
 	// 关闭 HTTP 服务器
+	var shutdownErr error
 	if c.server != nil {
 		if err := c.server.Shutdown(ctx); err != nil {
-
+			shutdownErr = fmt.Errorf("shutdown server: %w", err)
 		}
 	}
 
@@ -175,7 +185,14 @@ func (c *Consumer) Stop(ctx context.Context) error {
 	// 等待协程结束
 	c.wg.Wait()
 
-	return nil
+	return shutdownErr
+}
+
+// HealthCheck 检查 HTTP 服务是否仍在运行
+func (c *Consumer) HealthCheck(ctx context.Context) error {
+	c.serveErrMu.Lock()
+	defer c.serveErrMu.Unlock()
+	return c.serveErr
 }
 
 // Consume 消费消息
@@ -192,7 +209,7 @@ func (c *Consumer) Consume(ctx context.Context, msg *models.Message) error {
 	case c.broadcast <- formatted:
 
 	default:
-
+		event.Publish(event.MessageDropped(c.Name(), "broadcast queue full"))
 	}
 
 	return nil
@@ -214,16 +231,14 @@ func (c *Consumer) broadcastWorker() {
 			// 广播消息到所有客户端
 			data, err := json.Marshal(msg)
 			if err != nil {
-
+				event.Publish(event.PluginError(c.Name(), fmt.Errorf("marshal message: %w", err)))
 				continue
 			}
 
 			c.clientsMu.RLock()
 			for client := range c.clients {
-				if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
-
-					// 连接错误会在读取循环中处理
-				}
+				// 连接错误会在读取循环中处理
+				client.WriteMessage(websocket.TextMessage, data)
 			}
 			c.clientsMu.RUnlock()
 		}
@@ -245,7 +260,7 @@ func (c *Consumer) handleIndex(w http.ResponseWriter, r *http.Request) {
 func (c *Consumer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := c.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-
+		event.Publish(event.PluginError(c.Name(), fmt.Errorf("upgrade websocket: %w", err)))
 		return
 	}
 
@@ -310,11 +325,8 @@ func (c *Consumer) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
 	w.Header().Set("Cache-Control", "public, max-age=86400") // 缓存1天
 
-	// 将图片内容复制到响应
-	_, err = io.Copy(w, resp.Body)
-	if err != nil {
-
-	}
+	// 将图片内容复制到响应（客户端中途断开属于正常情况，不上报）
+	io.Copy(w, resp.Body)
 }
 
 // handleDefaultAvatar 提供默认头像
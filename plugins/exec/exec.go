@@ -0,0 +1,240 @@
+// Package exec 外部进程插件：启动外部程序，通过 stdin/stdout 的 JSON Lines 协议交互
+//
+// 每行一个 JSON 对象。主程序发送 {"id":1,"method":"init","config":{...}}，
+// 之后按插件类型发送 filter / transform / consume 请求（携带 message），
+// 退出前发送 shutdown。外部程序对每个请求回复一行带相同 id 的响应：
+//
+//	{"id":1}                              确认（init / consume / shutdown）
+//	{"id":2,"pass":false}                 过滤结果
+//	{"id":3,"message":{...}}              转换结果，省略 message 表示不修改
+//	{"id":4,"error":"...","retryable":true} 失败
+//
+// stderr 的输出限流后写入事件日志。进程意外退出后，下一次调用会重新启动它。
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// DefaultTimeout 单次请求的默认超时
+const DefaultTimeout = 5 * time.Second
+
+// Definition 外部进程插件定义
+type Definition struct {
+	Name    string            // 插件名称，在配置中引用
+	Type    plugin.PluginType // filter、transform 或 consumer
+	Command string            // 可执行文件
+	Args    []string          // 命令行参数
+	Env     []string          // 追加的环境变量（KEY=VALUE）
+	Dir     string            // 工作目录，为空时使用当前目录
+	Timeout time.Duration     // 单次请求超时，零值使用 DefaultTimeout
+}
+
+// Register 把外部进程插件注册到全局注册中心
+func Register(def Definition) error {
+	if def.Name == "" {
+		return fmt.Errorf("exec plugin without name")
+	}
+	if def.Command == "" {
+		return fmt.Errorf("exec plugin %s: command is required", def.Name)
+	}
+
+	switch def.Type {
+	case plugin.TypeFilter, plugin.TypeTransform, plugin.TypeConsumer:
+	default:
+		return fmt.Errorf("exec plugin %s: invalid type %q", def.Name, def.Type)
+	}
+
+	if def.Timeout <= 0 {
+		def.Timeout = DefaultTimeout
+	}
+
+	return plugin.Register(def.Name, func() plugin.Plugin {
+		return New(def)
+	}, plugin.PluginInfo{
+		Name:           def.Name,
+		Type:           def.Type,
+		ConfigTemplate: []plugin.ConfigField{},
+	})
+}
+
+// Plugin 外部进程插件，按定义的类型作为过滤器、转换器或消费者使用
+type Plugin struct {
+	*plugin.BasePlugin
+	def Definition
+
+	mu      sync.Mutex
+	proc    *process
+	stopped bool
+}
+
+// New 创建外部进程插件
+func New(def Definition) *Plugin {
+	if def.Timeout <= 0 {
+		def.Timeout = DefaultTimeout
+	}
+
+	return &Plugin{
+		BasePlugin: plugin.NewBasePlugin(def.Name, def.Type),
+		def:        def,
+	}
+}
+
+// Init 启动外部进程并发送 init 请求
+func (p *Plugin) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := p.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	proc, err := p.spawn()
+	if err != nil {
+		return err
+	}
+	p.proc = proc
+
+	return nil
+}
+
+// Stop 通知外部进程退出，超时后强制结束
+func (p *Plugin) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	proc := p.proc
+	p.proc = nil
+	p.stopped = true
+	p.mu.Unlock()
+
+	if proc == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.def.Timeout)
+	defer cancel()
+	proc.shutdown(ctx)
+
+	return nil
+}
+
+// HealthCheck 检查外部进程是否仍在运行
+func (p *Plugin) HealthCheck(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.proc == nil || !p.proc.alive() {
+		return fmt.Errorf("process not running")
+	}
+	return nil
+}
+
+// Filter 过滤消息，外部进程出错时拦截
+//
+// 管道调用 TryFilter，出错时按该过滤器的出错策略处理。
+func (p *Plugin) Filter(ctx context.Context, msg *models.Message) bool {
+	passed, err := p.TryFilter(ctx, msg)
+	if err != nil {
+		event.Publish(event.PluginError(p.Name(), err))
+		return false
+	}
+	return passed
+}
+
+// TryFilter 过滤消息，外部进程出错或未回复 pass 时返回错误
+func (p *Plugin) TryFilter(ctx context.Context, msg *models.Message) (bool, error) {
+	resp, err := p.call(ctx, methodFilter, msg)
+	if err != nil {
+		return false, err
+	}
+	if resp.Pass == nil {
+		return false, fmt.Errorf("filter response without pass")
+	}
+	return *resp.Pass, nil
+}
+
+// Transform 转换消息，响应未携带 message 时返回原消息
+func (p *Plugin) Transform(ctx context.Context, msg *models.Message) (*models.Message, error) {
+	resp, err := p.call(ctx, methodTransform, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Message) == 0 || string(resp.Message) == "null" {
+		return msg, nil
+	}
+
+	var out models.Message
+	if err := json.Unmarshal(resp.Message, &out); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+	out.Meta.Received = msg.Meta.Received // 接收时间以主程序为准
+	return &out, nil
+}
+
+// Consume 消费消息
+func (p *Plugin) Consume(ctx context.Context, msg *models.Message) error {
+	_, err := p.call(ctx, methodConsume, msg)
+	return err
+}
+
+// call 向外部进程发送携带消息的请求
+func (p *Plugin) call(ctx context.Context, method string, msg *models.Message) (*response, error) {
+	proc, err := p.process()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.def.Timeout)
+	defer cancel()
+
+	return proc.call(ctx, &request{Method: method, Message: msg})
+}
+
+// process 返回运行中的外部进程，进程已退出时重新启动
+func (p *Plugin) process() (*process, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return nil, fmt.Errorf("plugin stopped")
+	}
+	if p.proc != nil && p.proc.alive() {
+		return p.proc, nil
+	}
+
+	event.Publish(event.PluginStatus(p.Name(), "process exited, restarting"))
+
+	proc, err := p.spawn()
+	if err != nil {
+		return nil, err
+	}
+	p.proc = proc
+
+	return proc, nil
+}
+
+// spawn 启动外部进程并完成 init 握手（调用方需持有锁）
+func (p *Plugin) spawn() (*process, error) {
+	proc, err := startProcess(p.def)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.def.Timeout)
+	defer cancel()
+
+	if _, err := proc.call(ctx, &request{Method: methodInit, Config: p.GetConfig()}); err != nil {
+		proc.shutdown(ctx)
+		return nil, fmt.Errorf("init: %w", err)
+	}
+
+	return proc, nil
+}
@@ -0,0 +1,250 @@
+package exec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+)
+
+// maxLineSize 单行协议数据的最大长度
+const maxLineSize = 4 * 1024 * 1024
+
+// stderr 限流：每个窗口最多发布 stderrBurst 行，每行最多 maxStderrLine 个字符，
+// 超出的行只计数，窗口结束后汇总发布一次
+const (
+	stderrWindow  = 10 * time.Second
+	stderrBurst   = 20
+	maxStderrLine = 512
+)
+
+// process 一个运行中的外部进程
+//
+// 请求按行写入 stdin，响应按 ID 与等待中的请求匹配，stderr 的输出限流后作为插件状态发布。
+type process struct {
+	name  string
+	cmd   *osexec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	nextID  atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[uint64]chan *response
+
+	exited  chan struct{} // 进程退出后关闭
+	exitErr error
+}
+
+// startProcess 启动外部进程
+func startProcess(def Definition) (*process, error) {
+	cmd := osexec.Command(def.Command, def.Args...)
+	cmd.Dir = def.Dir
+	if len(def.Env) > 0 {
+		cmd.Env = append(os.Environ(), def.Env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", def.Command, err)
+	}
+
+	p := &process{
+		name:    def.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan *response),
+		exited:  make(chan struct{}),
+	}
+
+	// 读完 stdout 和 stderr 之后才能调用 Wait
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		p.readResponses(stdout)
+	}()
+	go func() {
+		defer readers.Done()
+		p.readStderr(stderr)
+	}()
+	go func() {
+		readers.Wait()
+		p.exitErr = cmd.Wait()
+		close(p.exited)
+	}()
+
+	return p, nil
+}
+
+// readResponses 读取 stdout 并把响应交给对应的请求
+func (p *process) readResponses(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			event.Publish(event.PluginError(p.name, fmt.Errorf("invalid response line: %w", err)))
+			continue
+		}
+
+		p.mu.Lock()
+		ch, exists := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+
+		// 已超时放弃的请求，响应直接丢弃
+		if exists {
+			ch <- &resp
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		event.Publish(event.PluginError(p.name, fmt.Errorf("read stdout: %w", err)))
+	}
+}
+
+// readStderr 把 stderr 的输出发布为插件状态（写入事件日志）
+//
+// 外部程序可能每条消息都写 stderr，按窗口限流并截断过长的行，避免淹没事件总线。
+func (p *process) readStderr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var windowStart time.Time
+	published, suppressed := 0, 0
+	for scanner.Scan() {
+		now := time.Now()
+		if now.Sub(windowStart) >= stderrWindow {
+			if suppressed > 0 {
+				event.Publish(event.PluginStatus(p.name, fmt.Sprintf("%d stderr lines suppressed", suppressed)))
+			}
+			windowStart = now
+			published, suppressed = 0, 0
+		}
+
+		if published >= stderrBurst {
+			suppressed++
+			continue
+		}
+		published++
+		event.Publish(event.PluginStatus(p.name, truncate(scanner.Text(), maxStderrLine)))
+	}
+
+	if suppressed > 0 {
+		event.Publish(event.PluginStatus(p.name, fmt.Sprintf("%d stderr lines suppressed", suppressed)))
+	}
+}
+
+// truncate 截断超过 limit 个字符的文本
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
+
+// call 发送请求并等待响应
+//
+// 外部进程返回的错误按 retryable 标记包装，进程退出视为可重试错误。
+func (p *process) call(ctx context.Context, req *request) (*response, error) {
+	req.ID = p.nextID.Add(1)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s request: %w", req.Method, err)
+	}
+
+	ch := make(chan *response, 1)
+	p.mu.Lock()
+	p.pending[req.ID] = ch
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, req.ID)
+		p.mu.Unlock()
+	}()
+
+	p.writeMu.Lock()
+	_, err = p.stdin.Write(append(data, '\n'))
+	p.writeMu.Unlock()
+	if err != nil {
+		return nil, plugin.Retryable(fmt.Errorf("write %s request: %w", req.Method, err))
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != "" {
+			err := errors.New(resp.Error)
+			if resp.Retryable {
+				err = plugin.Retryable(err)
+			}
+			return nil, err
+		}
+		return resp, nil
+
+	case <-p.exited:
+		return nil, p.exitError()
+
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s request: %w", req.Method, ctx.Err())
+	}
+}
+
+// alive 返回进程是否仍在运行
+func (p *process) alive() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// exitError 返回进程退出错误（进程已退出时调用）
+func (p *process) exitError() error {
+	if p.exitErr != nil {
+		return plugin.Retryable(fmt.Errorf("process exited: %w", p.exitErr))
+	}
+	return plugin.Retryable(errors.New("process exited"))
+}
+
+// shutdown 通知外部进程退出，ctx 取消前仍未退出则强制结束
+func (p *process) shutdown(ctx context.Context) {
+	if p.alive() {
+		p.call(ctx, &request{Method: methodShutdown})
+	}
+	p.stdin.Close()
+
+	select {
+	case <-p.exited:
+	case <-ctx.Done():
+		p.cmd.Process.Kill()
+		<-p.exited
+	}
+}
@@ -0,0 +1,35 @@
+package exec
+
+import (
+	"encoding/json"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// 协议方法
+const (
+	methodInit      = "init"      // 初始化，携带插件配置
+	methodFilter    = "filter"    // 过滤消息，响应 pass
+	methodTransform = "transform" // 转换消息，响应 message（省略表示不修改）
+	methodConsume   = "consume"   // 消费消息，响应确认
+	methodShutdown  = "shutdown"  // 退出前通知，响应确认后进程应自行退出
+)
+
+// request 主程序写入外部进程 stdin 的一行
+//
+// message 为 models.Message 的 JSON 信封：data 为平台原始数据，formatted 为格式化结果，meta 为发送者和时间。
+type request struct {
+	ID      uint64                 `json:"id"`
+	Method  string                 `json:"method"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+	Message *models.Message        `json:"message,omitempty"`
+}
+
+// response 外部进程写入 stdout 的一行，ID 与请求对应
+type response struct {
+	ID        uint64          `json:"id"`
+	Error     string          `json:"error,omitempty"`     // 非空表示失败
+	Retryable bool            `json:"retryable,omitempty"` // 失败可重试（仅消费者）
+	Pass      *bool           `json:"pass,omitempty"`      // 过滤结果
+	Message   json.RawMessage `json:"message,omitempty"`   // 转换结果，在 Transform 中解析
+}
@@ -0,0 +1,275 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/metrics"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// maxEntries 窗口内最多保留的记录数，超出时丢弃最旧的记录
+const maxEntries = 1000
+
+// 默认配置
+const (
+	defaultUserWindow     = 30 * time.Second
+	defaultGlobalWindow   = 5 * time.Second
+	defaultSimilarity     = 0.85
+	defaultReportInterval = 30 * time.Second
+)
+
+// record 窗口中的一条消息
+type record struct {
+	at   time.Time
+	user string
+	fp   *fingerprint
+}
+
+// Filter 重复与刷屏过滤器
+//
+// 在滑动时间窗口内拦截相同或相似的聊天内容：同一用户在 user_window 内、
+// 任意用户在 global_window 内。被拦截的消息同样记入窗口，持续刷屏会一直被拦截。
+// SuperChat 是付费消息，默认不参与去重，配置 superchat 后才检查。
+type Filter struct {
+	*plugin.BasePlugin
+
+	userWindow     time.Duration // 为 0 时不按用户去重
+	globalWindow   time.Duration // 为 0 时不做全局去重
+	similarity     float64
+	reportInterval time.Duration
+	superChat      bool // SuperChat 是否参与去重
+
+	mu      sync.Mutex
+	records []record
+	pending int // 上次报告以来拦截的消息数
+
+	suppressedUser   *metrics.Counter
+	suppressedGlobal *metrics.Counter
+
+	// 定期报告
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New 创建重复与刷屏过滤器
+func New() plugin.Plugin {
+	return &Filter{
+		BasePlugin: plugin.NewBasePlugin("dedup_filter", plugin.TypeFilter),
+	}
+}
+
+// Init 初始化插件
+func (f *Filter) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := f.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	f.userWindow = plugin.SecondsValue(config["user_window"], defaultUserWindow)
+	f.globalWindow = plugin.SecondsValue(config["global_window"], defaultGlobalWindow)
+	f.reportInterval = plugin.SecondsValue(config["report_interval"], defaultReportInterval)
+
+	f.superChat, _ = config["superchat"].(bool)
+	f.similarity = plugin.NumberValue(config["similarity"], defaultSimilarity)
+	if err := validateSimilarity(f.similarity); err != nil {
+		return err
+	}
+
+	f.suppressedUser = metrics.DefaultRegistry.Counter("dmnotifier_dedup_suppressed_total", "Messages suppressed as duplicates.", metrics.Labels{"scope": "user"})
+	f.suppressedGlobal = metrics.DefaultRegistry.Counter("dmnotifier_dedup_suppressed_total", "Messages suppressed as duplicates.", metrics.Labels{"scope": "global"})
+
+	return nil
+}
+
+// Start 启动定期报告
+func (f *Filter) Start(ctx context.Context) error {
+	if f.reportInterval <= 0 {
+		return nil
+	}
+
+	reportCtx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	f.wg.Add(1)
+	go f.reportLoop(reportCtx)
+
+	return nil
+}
+
+// Stop 停止定期报告，并报告剩余的拦截数
+func (f *Filter) Stop(ctx context.Context) error {
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.wg.Wait()
+	f.report()
+	return nil
+}
+
+// Filter 过滤消息
+//
+// 只检查聊天内容（配置 superchat 时包括 SuperChat），其余消息总是通过。
+func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
+	var user, content string
+	switch d := msg.SourceData().(type) {
+	case *models.ChatData:
+		user, content = d.Name, d.Content
+	case *models.SuperChatData:
+		if !f.superChat {
+			return true
+		}
+		user, content = d.Name, d.Content
+	default:
+		return true
+	}
+	if content == "" {
+		return true
+	}
+
+	// 有用户 ID 时按 ID 区分，改名不影响去重
+	if sender := msg.Sender(); sender != nil && sender.ID != "" {
+		user = string(msg.Platform) + "#" + sender.ID
+	} else {
+		user = string(msg.Platform) + "/" + user
+	}
+	now := time.Now()
+	current := record{at: now, user: user, fp: newFingerprint(content)}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prune(now)
+	scope := f.match(current)
+	f.records = append(f.records, current)
+	if len(f.records) > maxEntries {
+		f.records = f.records[len(f.records)-maxEntries:]
+	}
+
+	switch scope {
+	case "user":
+		f.suppressedUser.Inc()
+	case "global":
+		f.suppressedGlobal.Inc()
+	default:
+		return true
+	}
+
+	f.pending++
+	return false
+}
+
+// match 返回命中的窗口（"user" 或 "global"），未命中时返回空字符串，调用方持有锁
+func (f *Filter) match(current record) string {
+	for i := len(f.records) - 1; i >= 0; i-- {
+		prev := f.records[i]
+		age := current.at.Sub(prev.at)
+
+		sameUser := f.userWindow > 0 && prev.user == current.user && age <= f.userWindow
+		global := f.globalWindow > 0 && age <= f.globalWindow
+		if !sameUser && !global {
+			continue
+		}
+
+		if current.fp.similarity(prev.fp) < f.similarity {
+			continue
+		}
+		if sameUser {
+			return "user"
+		}
+		return "global"
+	}
+	return ""
+}
+
+// prune 丢弃超出两个窗口的记录，调用方持有锁
+func (f *Filter) prune(now time.Time) {
+	keep := max(f.userWindow, f.globalWindow)
+	i := 0
+	for i < len(f.records) && now.Sub(f.records[i].at) > keep {
+		i++
+	}
+	f.records = f.records[i:]
+}
+
+// reportLoop 定期发布拦截数
+func (f *Filter) reportLoop(ctx context.Context) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.report()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// report 有新的拦截时发布状态事件
+func (f *Filter) report() {
+	f.mu.Lock()
+	n := f.pending
+	f.pending = 0
+	f.mu.Unlock()
+
+	if n > 0 {
+		event.Publish(event.PluginStatus(f.Name(), fmt.Sprintf("suppressed %d duplicate messages", n)))
+	}
+}
+
+// validateSimilarity 检查相似度阈值
+func validateSimilarity(similarity float64) error {
+	if similarity <= 0 || similarity > 1 {
+		return fmt.Errorf("invalid similarity %v (want 0 < similarity <= 1)", similarity)
+	}
+	return nil
+}
+
+func init() {
+	plugin.Register("dedup_filter", New, plugin.PluginInfo{
+		Name: "dedup_filter",
+		Type: plugin.TypeFilter,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:    "user_window",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultUserWindow.Seconds(),
+				Desc:    "同一用户重复内容的窗口（秒），0 表示不按用户去重",
+			},
+			{
+				Name:    "global_window",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultGlobalWindow.Seconds(),
+				Desc:    "所有用户重复内容的窗口（秒），0 表示不做全局去重",
+			},
+			{
+				Name:    "similarity",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultSimilarity,
+				Desc:    "相似度阈值（0-1），1 表示只拦截归一化后完全相同的内容",
+				Validate: func(value interface{}) error {
+					return validateSimilarity(plugin.NumberValue(value, defaultSimilarity))
+				},
+			},
+			{
+				Name:    "report_interval",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultReportInterval.Seconds(),
+				Desc:    "报告拦截数的间隔（秒），0 表示只在停止时报告",
+			},
+			{
+				Name:    "superchat",
+				Type:    plugin.FieldTypeBool,
+				Default: false,
+				Desc:    "SuperChat 同样去重（默认付费消息总是通过）",
+			},
+		},
+	})
+}
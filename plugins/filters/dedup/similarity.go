@@ -0,0 +1,69 @@
+package dedup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxRepeat 连续重复字符最多保留的个数（"2333333" 与 "23333" 归一化后相同）
+const maxRepeat = 2
+
+// fingerprint 归一化后的文本及其字符二元组，用于近似比较
+type fingerprint struct {
+	text    string
+	bigrams map[[2]rune]int
+	total   int
+}
+
+// newFingerprint 归一化文本：忽略大小写、空白和标点，连续重复字符截断为 maxRepeat 个
+func newFingerprint(content string) *fingerprint {
+	var b strings.Builder
+	var last rune
+	repeat := 0
+	for _, r := range strings.ToLower(content) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		if r == last {
+			repeat++
+			if repeat >= maxRepeat {
+				continue
+			}
+		} else {
+			last, repeat = r, 0
+		}
+		b.WriteRune(r)
+	}
+
+	// 只有标点的消息（如 "？？？"）按原文比较
+	text := b.String()
+	if text == "" {
+		text = strings.TrimSpace(content)
+	}
+
+	fp := &fingerprint{text: text, bigrams: make(map[[2]rune]int)}
+	runes := []rune(fp.text)
+	for i := 0; i+1 < len(runes); i++ {
+		fp.bigrams[[2]rune{runes[i], runes[i+1]}]++
+		fp.total++
+	}
+	return fp
+}
+
+// similarity 返回两段文本的相似度（0 到 1），按字符二元组的 Dice 系数计算
+func (fp *fingerprint) similarity(other *fingerprint) float64 {
+	if fp.text == other.text {
+		return 1
+	}
+	if fp.total == 0 || other.total == 0 {
+		return 0
+	}
+
+	common := 0
+	for bigram, n := range fp.bigrams {
+		if m := other.bigrams[bigram]; m > 0 {
+			common += min(n, m)
+		}
+	}
+	return 2 * float64(common) / float64(fp.total+other.total)
+}
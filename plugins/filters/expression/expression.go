@@ -0,0 +1,70 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xifan2333/dmnotifier/internal/expr"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// Filter 表达式过滤器，表达式为真的消息通过
+type Filter struct {
+	*plugin.BasePlugin
+	program *expr.Program // 为 nil 时放行所有消息
+}
+
+// New 创建表达式过滤器
+func New() plugin.Plugin {
+	return &Filter{
+		BasePlugin: plugin.NewBasePlugin("expression_filter", plugin.TypeFilter),
+	}
+}
+
+// Init 初始化插件，表达式无法编译时返回错误
+func (f *Filter) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := f.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	source, _ := config["expression"].(string)
+	program, err := expr.CompileMessage(source)
+	if err != nil {
+		return fmt.Errorf("compile expression: %w", err)
+	}
+	f.program = program
+
+	return nil
+}
+
+// Filter 过滤消息
+func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
+	if f.program == nil {
+		return true
+	}
+	return f.program.Eval(expr.MessageEnv(msg))
+}
+
+// validateExpression 配置保存前检查表达式能否编译
+func validateExpression(value interface{}) error {
+	source, _ := value.(string)
+	_, err := expr.CompileMessage(source)
+	return err
+}
+
+func init() {
+	plugin.Register("expression_filter", New, plugin.PluginInfo{
+		Name: "expression_filter",
+		Type: plugin.TypeFilter,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:     "expression",
+				Type:     plugin.FieldTypeString,
+				Default:  "",
+				Desc:     `过滤表达式，为真时通过（如：type == "Gift" && value >= 10 || content ~ "主播"）`,
+				Validate: validateExpression,
+			},
+		},
+	})
+}
@@ -0,0 +1,315 @@
+package keyword
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/event"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// reloadInterval 检查列表文件变化的间隔
+const reloadInterval = 2 * time.Second
+
+// 过滤模式
+const (
+	modeBlock = "block" // 命中的消息被拦截
+	modeAllow = "allow" // 只有命中的消息通过
+)
+
+// 匹配目标
+const (
+	targetContent = "content" // 聊天和 SuperChat 内容
+	targetUser    = "user"    // 用户名
+	targetBoth    = "both"    // 内容和用户名
+)
+
+// Filter 关键词与正则过滤器
+//
+// 关键词按子串匹配，正则按 Go regexp 语法匹配。列表来自配置和外部文件，
+// 文件变化时自动重新加载，加载失败时保留旧列表。
+type Filter struct {
+	*plugin.BasePlugin
+
+	mode   string
+	target string
+	source listSource
+
+	matcher atomic.Pointer[matcher]
+
+	// 文件监视
+	stamps [2]fileStamp // 最近一次加载时的文件状态
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New 创建关键词过滤器
+func New() plugin.Plugin {
+	return &Filter{
+		BasePlugin: plugin.NewBasePlugin("keyword_filter", plugin.TypeFilter),
+	}
+}
+
+// Init 初始化插件，列表文件不存在或正则无效时返回错误
+func (f *Filter) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := f.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	f.mode = plugin.StringValue(config["mode"], modeBlock)
+	if err := validateMode(f.mode); err != nil {
+		return err
+	}
+
+	f.target = plugin.StringValue(config["match"], targetContent)
+	if err := validateTarget(f.target); err != nil {
+		return err
+	}
+
+	caseSensitive, _ := config["case_sensitive"].(bool)
+	f.source = listSource{
+		keywords:      stringList(config["keywords"], keywordSeparators),
+		patterns:      stringList(config["patterns"], patternSeparators),
+		keywordsFile:  expandHome(plugin.StringValue(config["keywords_file"], "")),
+		patternsFile:  expandHome(plugin.StringValue(config["patterns_file"], "")),
+		caseSensitive: caseSensitive,
+	}
+
+	f.stamps = f.source.modTimes()
+	m, err := f.source.load()
+	if err != nil {
+		return err
+	}
+	f.matcher.Store(m)
+
+	return nil
+}
+
+// Start 配置了列表文件时启动文件监视
+func (f *Filter) Start(ctx context.Context) error {
+	if !f.source.hasFiles() {
+		return nil
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	f.wg.Add(1)
+	go f.watch(watchCtx)
+
+	return nil
+}
+
+// Stop 停止文件监视
+func (f *Filter) Stop(ctx context.Context) error {
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.wg.Wait()
+	return nil
+}
+
+// Filter 过滤消息
+//
+// 没有可匹配文本的消息（如直播结束）总是通过。
+func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
+	texts := f.texts(msg)
+	if len(texts) == 0 {
+		return true
+	}
+
+	m := f.matcher.Load()
+	matched := false
+	for _, text := range texts {
+		if m.match(text) {
+			matched = true
+			break
+		}
+	}
+
+	if f.mode == modeAllow {
+		return matched
+	}
+	return !matched
+}
+
+// texts 返回消息中需要匹配的文本
+func (f *Filter) texts(msg *models.Message) []string {
+	var user, content string
+	switch d := msg.SourceData().(type) {
+	case *models.ChatData:
+		user, content = d.Name, d.Content
+	case *models.SuperChatData:
+		user, content = d.Name, d.Content
+	case *models.GiftData:
+		user = d.Name
+	case *models.LikeData:
+		user = d.Name
+	case *models.EnterRoomData:
+		user = d.Name
+	case *models.SubscribeData:
+		user = d.Name
+	case *models.GuardData:
+		user = d.Name
+	case *models.ShareData:
+		user = d.Name
+	case *models.FollowData:
+		user = d.Name
+	case *models.UnknownData:
+		user = d.Name
+	}
+
+	texts := make([]string, 0, 2)
+	if content != "" && f.target != targetUser {
+		texts = append(texts, content)
+	}
+	if user != "" && f.target != targetContent {
+		texts = append(texts, user)
+	}
+	return texts
+}
+
+// watch 定期检查列表文件，变化时重新加载
+func (f *Filter) watch(ctx context.Context) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			current := f.source.modTimes()
+			if current == f.stamps {
+				continue
+			}
+			f.stamps = current
+
+			m, err := f.source.load()
+			if err != nil {
+				event.Publish(event.PluginError(f.Name(), fmt.Errorf("reload lists: %w", err)))
+				continue
+			}
+			f.matcher.Store(m)
+			event.Publish(event.PluginStatus(f.Name(), fmt.Sprintf("lists reloaded (%d keywords, %d patterns)", len(m.keywords), len(m.patterns))))
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// validateMode 检查过滤模式
+func validateMode(mode string) error {
+	if mode != modeBlock && mode != modeAllow {
+		return fmt.Errorf("invalid mode %q (want %s or %s)", mode, modeBlock, modeAllow)
+	}
+	return nil
+}
+
+// validateTarget 检查匹配目标
+func validateTarget(target string) error {
+	switch target {
+	case targetContent, targetUser, targetBoth:
+		return nil
+	}
+	return fmt.Errorf("invalid match target %q (want %s, %s or %s)", target, targetContent, targetUser, targetBoth)
+}
+
+// validatePatterns 配置保存前检查正则能否编译
+func validatePatterns(value interface{}) error {
+	_, err := compilePatterns(stringList(value, patternSeparators), false)
+	return err
+}
+
+// 字符串形式的列表配置的分隔符；正则中可能出现逗号（如 6{3,}），只按换行分隔
+const (
+	keywordSeparators = ",\n"
+	patternSeparators = "\n"
+)
+
+// stringList 读取列表配置：YAML 列表，或以 separators 中任一字符分隔的字符串
+func stringList(value interface{}, separators string) []string {
+	var items []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	case []string:
+		items = v
+	case string:
+		items = strings.FieldsFunc(v, func(r rune) bool {
+			return strings.ContainsRune(separators, r)
+		})
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func init() {
+	plugin.Register("keyword_filter", New, plugin.PluginInfo{
+		Name: "keyword_filter",
+		Type: plugin.TypeFilter,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:    "mode",
+				Type:    plugin.FieldTypeEnum,
+				Default: modeBlock,
+				Desc:    "block: 拦截命中的消息, allow: 只放行命中的消息",
+				Options: []string{modeBlock, modeAllow},
+			},
+			{
+				Name:    "match",
+				Type:    plugin.FieldTypeEnum,
+				Default: targetContent,
+				Desc:    "匹配内容、用户名或两者",
+				Options: []string{targetContent, targetUser, targetBoth},
+			},
+			{
+				Name:    "keywords",
+				Type:    plugin.FieldTypeString,
+				Default: "",
+				Desc:    "关键词，逗号分隔（子串匹配）",
+			},
+			{
+				Name:     "patterns",
+				Type:     plugin.FieldTypeString,
+				Default:  "",
+				Desc:     "正则表达式，多个按行分隔（单行时可用 | 组合）",
+				Validate: validatePatterns,
+			},
+			{
+				Name:    "keywords_file",
+				Type:    plugin.FieldTypeString,
+				Default: "",
+				Desc:    "关键词文件，每行一个，# 开头为注释，修改后自动重新加载",
+			},
+			{
+				Name:    "patterns_file",
+				Type:    plugin.FieldTypeString,
+				Default: "",
+				Desc:    "正则文件，每行一个，# 开头为注释，修改后自动重新加载",
+			},
+			{
+				Name:    "case_sensitive",
+				Type:    plugin.FieldTypeBool,
+				Default: false,
+				Desc:    "区分大小写",
+			},
+		},
+	})
+}
@@ -0,0 +1,169 @@
+package keyword
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// listSource 关键词和正则的来源：配置中的列表加上外部文件
+type listSource struct {
+	keywords      []string
+	patterns      []string
+	keywordsFile  string
+	patternsFile  string
+	caseSensitive bool
+}
+
+// fileStamp 用于判断文件是否变化
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// hasFiles 返回是否配置了列表文件
+func (s listSource) hasFiles() bool {
+	return s.keywordsFile != "" || s.patternsFile != ""
+}
+
+// modTimes 返回列表文件的当前状态
+func (s listSource) modTimes() [2]fileStamp {
+	return [2]fileStamp{stamp(s.keywordsFile), stamp(s.patternsFile)}
+}
+
+// stamp 返回文件状态，文件不存在时返回零值
+func stamp(path string) fileStamp {
+	if path == "" {
+		return fileStamp{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+}
+
+// load 合并配置和文件中的列表，构建匹配器
+func (s listSource) load() (*matcher, error) {
+	keywords := append([]string{}, s.keywords...)
+	patterns := append([]string{}, s.patterns...)
+
+	if s.keywordsFile != "" {
+		lines, err := readLines(s.keywordsFile)
+		if err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, lines...)
+	}
+
+	if s.patternsFile != "" {
+		lines, err := readLines(s.patternsFile)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, lines...)
+	}
+
+	compiled, err := compilePatterns(patterns, s.caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.caseSensitive {
+		for i, keyword := range keywords {
+			keywords[i] = strings.ToLower(keyword)
+		}
+	}
+
+	return &matcher{
+		keywords:      keywords,
+		patterns:      compiled,
+		caseSensitive: s.caseSensitive,
+	}, nil
+}
+
+// expandHome 把路径开头的 ~/ 展开为用户主目录
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// readLines 读取列表文件：每行一项，忽略空行和 # 开头的注释
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open list file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read list file %s: %w", path, err)
+	}
+
+	return lines, nil
+}
+
+// compilePatterns 编译正则列表，不区分大小写时加上 (?i)
+func compilePatterns(patterns []string, caseSensitive bool) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr := pattern
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matcher 不可变的匹配器，重新加载时整体替换
+type matcher struct {
+	keywords      []string
+	patterns      []*regexp.Regexp
+	caseSensitive bool
+}
+
+// match 返回文本是否命中任一关键词或正则
+func (m *matcher) match(text string) bool {
+	lowered := text
+	if !m.caseSensitive {
+		lowered = strings.ToLower(text)
+	}
+
+	for _, keyword := range m.keywords {
+		if strings.Contains(lowered, keyword) {
+			return true
+		}
+	}
+
+	for _, re := range m.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
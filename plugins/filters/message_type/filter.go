@@ -11,6 +11,7 @@ import (
 type Filter struct {
 	*plugin.BasePlugin
 	allowedTypes map[models.MessageType]bool
+	allowAll     bool
 }
 
 // New 创建消息类型过滤器
@@ -36,23 +37,19 @@ func (f *Filter) Init(ctx context.Context, config map[string]interface{}) error
 		}
 	}
 
-	// 如果没有配置，则允许所有类型
-	if len(f.allowedTypes) == 0 {
-		f.allowedTypes[models.TypeChat] = true
-		f.allowedTypes[models.TypeGift] = true
-		f.allowedTypes[models.TypeLike] = true
-		f.allowedTypes[models.TypeEnterRoom] = true
-		f.allowedTypes[models.TypeSubscribe] = true
-		f.allowedTypes[models.TypeSuperChat] = true
-		f.allowedTypes[models.TypeEndLive] = true
-	}
+	// 如果没有配置，则允许所有类型（包括未知类型）
+	f.allowAll = len(f.allowedTypes) == 0
 
 	return nil
 }
 
 // Filter 过滤消息
 func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
-	return f.allowedTypes[msg.Type]
+	if f.allowAll || f.allowedTypes[msg.Type] {
+		return true
+	}
+	// Unknown 匹配所有未知类型
+	return !msg.Type.IsValid() && f.allowedTypes[models.TypeUnknown]
 }
 
 func init() {
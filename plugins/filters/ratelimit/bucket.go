@@ -0,0 +1,37 @@
+package ratelimit
+
+import "time"
+
+// bucket 令牌桶：以 rate 个/秒补充，最多存 burst 个
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newBucket 创建装满的令牌桶
+func newBucket(burst float64, now time.Time) *bucket {
+	return &bucket{tokens: burst, last: now}
+}
+
+// refill 按经过的时间补充令牌
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+elapsed*rate)
+	}
+	b.last = now
+}
+
+// ready 返回是否有可用的令牌，调用前先 refill
+func (b *bucket) ready() bool {
+	return b.tokens >= 1
+}
+
+// take 消耗一个令牌
+func (b *bucket) take() {
+	b.tokens--
+}
+
+// full 返回令牌桶是否已装满（可以安全丢弃）
+func (b *bucket) full(burst float64) bool {
+	return b.tokens >= burst
+}
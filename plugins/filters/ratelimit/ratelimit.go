@@ -0,0 +1,245 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/expr"
+	"github.com/xifan2333/dmnotifier/internal/metrics"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// sweepThreshold 用户令牌桶超过该数量时清理已装满的桶
+const sweepThreshold = 1000
+
+// 默认配置
+const (
+	defaultRate   = 1.0
+	defaultBurst  = 5.0
+	defaultBypass = `type == "SuperChat" || type == "Guard" || (type == "Gift" || type == "Subscribe") && price > 0`
+)
+
+// Filter 令牌桶限流过滤器
+//
+// 全局令牌桶限制通过的总速率，可选的用户令牌桶限制单个用户的速率，
+// 满足 bypass 表达式的高优先级消息不受限流且不消耗令牌，默认为全部付费消息
+// （SuperChat、大航海、付费礼物和订阅）。
+// 每个插件实例有独立的令牌桶，放在哪个消费者的链上就限制哪个消费者。
+type Filter struct {
+	*plugin.BasePlugin
+
+	rate      float64 // 全局补充速率（个/秒），为 0 时不做全局限流
+	burst     float64
+	userRate  float64 // 用户补充速率（个/秒），为 0 时不按用户限流
+	userBurst float64
+	bypass    *expr.Program // 为 nil 时没有优先消息
+
+	mu     sync.Mutex
+	global *bucket
+	users  map[string]*bucket
+
+	limitedGlobal *metrics.Counter
+	limitedUser   *metrics.Counter
+}
+
+// New 创建限流过滤器
+func New() plugin.Plugin {
+	return &Filter{
+		BasePlugin: plugin.NewBasePlugin("rate_limit_filter", plugin.TypeFilter),
+	}
+}
+
+// Init 初始化插件
+func (f *Filter) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := f.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	f.rate = plugin.NumberValue(config["rate"], defaultRate)
+	f.burst = plugin.NumberValue(config["burst"], defaultBurst)
+	f.userRate = plugin.NumberValue(config["user_rate"], 0)
+	f.userBurst = plugin.NumberValue(config["user_burst"], 1)
+	if err := validateBucket("rate", f.rate, f.burst); err != nil {
+		return err
+	}
+	if err := validateBucket("user_rate", f.userRate, f.userBurst); err != nil {
+		return err
+	}
+
+	source, ok := config["bypass"].(string)
+	if !ok {
+		source = defaultBypass
+	}
+	program, err := expr.CompileMessage(source)
+	if err != nil {
+		return fmt.Errorf("compile bypass expression: %w", err)
+	}
+	f.bypass = program
+
+	now := time.Now()
+	f.global = newBucket(f.burst, now)
+	f.users = make(map[string]*bucket)
+
+	f.limitedGlobal = metrics.DefaultRegistry.Counter("dmnotifier_ratelimit_limited_total", "Messages rejected by the rate limiter.", metrics.Labels{"scope": "global"})
+	f.limitedUser = metrics.DefaultRegistry.Counter("dmnotifier_ratelimit_limited_total", "Messages rejected by the rate limiter.", metrics.Labels{"scope": "user"})
+
+	return nil
+}
+
+// Filter 过滤消息，两个令牌桶都有令牌时才通过并各消耗一个
+func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
+	if f.bypass != nil && f.bypass.Eval(expr.MessageEnv(msg)) {
+		return true
+	}
+
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var userBucket *bucket
+	if f.userRate > 0 {
+		if key, ok := userKey(msg); ok {
+			userBucket = f.userBucket(key, now)
+			if !userBucket.ready() {
+				f.limitedUser.Inc()
+				return false
+			}
+		}
+	}
+
+	if f.rate > 0 {
+		f.global.refill(now, f.rate, f.burst)
+		if !f.global.ready() {
+			f.limitedGlobal.Inc()
+			return false
+		}
+		f.global.take()
+	}
+
+	if userBucket != nil {
+		userBucket.take()
+	}
+	return true
+}
+
+// userBucket 返回补充后的用户令牌桶，调用方持有锁
+func (f *Filter) userBucket(key string, now time.Time) *bucket {
+	b, exists := f.users[key]
+	if !exists {
+		if len(f.users) >= sweepThreshold {
+			f.sweep(now)
+		}
+		b = newBucket(f.userBurst, now)
+		f.users[key] = b
+		return b
+	}
+
+	b.refill(now, f.userRate, f.userBurst)
+	return b
+}
+
+// sweep 丢弃已装满的用户令牌桶，重新创建的桶同样是满的，不影响限流结果
+func (f *Filter) sweep(now time.Time) {
+	for key, b := range f.users {
+		b.refill(now, f.userRate, f.userBurst)
+		if b.full(f.userBurst) {
+			delete(f.users, key)
+		}
+	}
+}
+
+// userKey 用户令牌桶的键：有用户 ID 时按 ID，否则按用户名；没有发送者的消息返回 false
+func userKey(msg *models.Message) (string, bool) {
+	user := msg.Sender()
+	switch {
+	case user == nil:
+		return "", false
+	case user.ID != "":
+		return string(msg.Platform) + "#" + user.ID, true
+	case user.Name != "":
+		return string(msg.Platform) + "/" + user.Name, true
+	}
+	return "", false
+}
+
+// validateBucket 检查令牌桶配置
+func validateBucket(name string, rate, burst float64) error {
+	if rate < 0 {
+		return fmt.Errorf("invalid %s %v (want >= 0)", name, rate)
+	}
+	if rate > 0 && burst < 1 {
+		return fmt.Errorf("invalid burst %v for %s (want >= 1)", burst, name)
+	}
+	return nil
+}
+
+// validateBypass 配置保存前检查优先表达式能否编译
+func validateBypass(value interface{}) error {
+	source, _ := value.(string)
+	_, err := expr.CompileMessage(source)
+	return err
+}
+
+// validateRate 配置保存前检查速率
+func validateRate(value interface{}) error {
+	if rate := plugin.NumberValue(value, 0); rate < 0 {
+		return fmt.Errorf("rate must be >= 0, got %v", rate)
+	}
+	return nil
+}
+
+// validateBurst 配置保存前检查容量
+func validateBurst(value interface{}) error {
+	if burst := plugin.NumberValue(value, 1); burst < 1 {
+		return fmt.Errorf("burst must be >= 1, got %v", burst)
+	}
+	return nil
+}
+
+func init() {
+	plugin.Register("rate_limit_filter", New, plugin.PluginInfo{
+		Name: "rate_limit_filter",
+		Type: plugin.TypeFilter,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:     "rate",
+				Type:     plugin.FieldTypeNumber,
+				Default:  defaultRate,
+				Desc:     "全局每秒通过的消息数，0 表示不做全局限流",
+				Validate: validateRate,
+			},
+			{
+				Name:     "burst",
+				Type:     plugin.FieldTypeNumber,
+				Default:  defaultBurst,
+				Desc:     "全局突发容量",
+				Validate: validateBurst,
+			},
+			{
+				Name:     "user_rate",
+				Type:     plugin.FieldTypeNumber,
+				Default:  0,
+				Desc:     "每个用户每秒通过的消息数，0 表示不按用户限流",
+				Validate: validateRate,
+			},
+			{
+				Name:     "user_burst",
+				Type:     plugin.FieldTypeNumber,
+				Default:  1,
+				Desc:     "每个用户的突发容量",
+				Validate: validateBurst,
+			},
+			{
+				Name:     "bypass",
+				Type:     plugin.FieldTypeString,
+				Default:  defaultBypass,
+				Desc:     "优先消息表达式，为真时不受限流（语法同 expression_filter，跨平台比较 value 需先经过 currency_transform；留空表示没有优先消息）",
+				Validate: validateBypass,
+			},
+		},
+	})
+}
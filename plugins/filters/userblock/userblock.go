@@ -0,0 +1,42 @@
+package userblock
+
+import (
+	"context"
+
+	"github.com/xifan2333/dmnotifier/internal/blocklist"
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// Filter 用户屏蔽过滤器，拦截屏蔽列表中用户发出的所有消息
+//
+// 屏蔽列表在 TUI 中维护（消息面板按 b 屏蔽、按 B 管理），修改立即生效，无需重载。
+type Filter struct {
+	*plugin.BasePlugin
+	store *blocklist.Store
+}
+
+// New 创建用户屏蔽过滤器
+func New() plugin.Plugin {
+	return &Filter{
+		BasePlugin: plugin.NewBasePlugin("user_block_filter", plugin.TypeFilter),
+		store:      blocklist.Default,
+	}
+}
+
+// Filter 过滤消息
+func (f *Filter) Filter(ctx context.Context, msg *models.Message) bool {
+	user, ok := blocklist.FromMessage(msg)
+	if !ok {
+		return true
+	}
+	return !f.store.Contains(user)
+}
+
+func init() {
+	plugin.Register("user_block_filter", New, plugin.PluginInfo{
+		Name:           "user_block_filter",
+		Type:           plugin.TypeFilter,
+		ConfigTemplate: []plugin.ConfigField{},
+	})
+}
@@ -0,0 +1,269 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// 默认配置
+const (
+	defaultGiftWindow = 3 * time.Second
+	defaultLikeWindow = 10 * time.Second
+)
+
+// minFlushInterval Flush 间隔下限
+const minFlushInterval = 100 * time.Millisecond
+
+// giftCombo 同一用户连续赠送同一礼物的累计
+type giftCombo struct {
+	msg   *models.Message // 第一条礼物消息
+	data  models.GiftData // 累计后的数据
+	total float64         // 累计总价
+	last  time.Time       // 最近一次赠送时间
+}
+
+// likeBatch 同一用户在一个窗口内的点赞累计
+type likeBatch struct {
+	msg   *models.Message // 第一条点赞消息
+	data  models.LikeData // 累计后的数据
+	start time.Time       // 窗口开始时间
+}
+
+// Transform 礼物连击与点赞聚合转换器
+//
+// 同一用户连续赠送同一礼物时合并为一条（数量和总价累加），超过 gift_window 没有
+// 新的赠送或改送其他礼物时输出；点赞按用户在 like_window 内合并为一条。
+// 需要放在 format_transform 之前，其余消息原样通过。
+type Transform struct {
+	*plugin.BasePlugin
+
+	giftWindow time.Duration // 为 0 时不聚合礼物
+	likeWindow time.Duration // 为 0 时不聚合点赞
+
+	// 只在管道的阶段协程中访问，无需加锁
+	gifts map[string]*giftCombo
+	likes map[string]*likeBatch
+	order []string // 暂存项的创建顺序，输出时保持先后
+}
+
+// New 创建聚合转换器
+func New() plugin.Plugin {
+	return &Transform{
+		BasePlugin: plugin.NewBasePlugin("aggregate_transform", plugin.TypeTransform),
+	}
+}
+
+// Init 初始化插件
+func (t *Transform) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := t.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	t.giftWindow = plugin.SecondsValue(config["gift_window"], defaultGiftWindow)
+	t.likeWindow = plugin.SecondsValue(config["like_window"], defaultLikeWindow)
+	t.gifts = make(map[string]*giftCombo)
+	t.likes = make(map[string]*likeBatch)
+	t.order = nil
+
+	return nil
+}
+
+// Transform 单条转换接口，管道使用 TransformBatch；不聚合，原样返回
+func (t *Transform) Transform(ctx context.Context, msg *models.Message) (*models.Message, error) {
+	return msg, nil
+}
+
+// TransformBatch 暂存礼物和点赞，返回需要立即输出的消息
+func (t *Transform) TransformBatch(ctx context.Context, msg *models.Message) ([]*models.Message, error) {
+	now := time.Now()
+
+	switch data := msg.Data.(type) {
+	case *models.GiftData:
+		if t.giftWindow > 0 {
+			return t.addGift(msg, data, now), nil
+		}
+	case *models.LikeData:
+		if t.likeWindow > 0 {
+			t.addLike(msg, data, now)
+			return nil, nil
+		}
+	}
+
+	return []*models.Message{msg}, nil
+}
+
+// FlushInterval 返回检查窗口的间隔
+func (t *Transform) FlushInterval() time.Duration {
+	interval := time.Duration(0)
+	for _, window := range []time.Duration{t.giftWindow, t.likeWindow} {
+		if window > 0 && (interval == 0 || window < interval) {
+			interval = window
+		}
+	}
+	return max(interval/4, minFlushInterval)
+}
+
+// Flush 输出窗口已关闭的聚合消息，final 时输出全部
+func (t *Transform) Flush(ctx context.Context, final bool) ([]*models.Message, error) {
+	now := time.Now()
+
+	var out []*models.Message
+	order := t.order[:0]
+	for _, key := range t.order {
+		if combo, ok := t.gifts[key]; ok {
+			if final || now.Sub(combo.last) >= t.giftWindow {
+				out = append(out, combo.message())
+				delete(t.gifts, key)
+				continue
+			}
+		}
+		if batch, ok := t.likes[key]; ok {
+			if final || now.Sub(batch.start) >= t.likeWindow {
+				out = append(out, batch.message())
+				delete(t.likes, key)
+				continue
+			}
+		}
+		order = append(order, key)
+	}
+	t.order = order
+
+	return out, nil
+}
+
+// addGift 累计礼物，同一用户改送其他礼物时输出之前的连击
+func (t *Transform) addGift(msg *models.Message, data *models.GiftData, now time.Time) []*models.Message {
+	key := "gift:" + userKey(msg, data.Name)
+
+	var out []*models.Message
+	if combo, ok := t.gifts[key]; ok {
+		if combo.data.Item == data.Item {
+			combo.data.Num += data.Num
+			combo.total += data.Price * float64(data.Num)
+			combo.addValue(data.Value)
+			combo.last = now
+			return nil
+		}
+
+		out = append(out, combo.message())
+		t.remove(key)
+	}
+
+	combo := &giftCombo{
+		msg:   msg,
+		data:  *data,
+		total: data.Price * float64(data.Num),
+		last:  now,
+	}
+	if data.Value != nil {
+		value := *data.Value
+		combo.data.Value = &value
+	}
+	t.gifts[key] = combo
+	t.order = append(t.order, key)
+	return out
+}
+
+// addLike 累计点赞
+func (t *Transform) addLike(msg *models.Message, data *models.LikeData, now time.Time) {
+	key := "like:" + userKey(msg, data.Name)
+
+	if batch, ok := t.likes[key]; ok {
+		batch.data.Count += data.Count
+		return
+	}
+
+	t.likes[key] = &likeBatch{msg: msg, data: *data, start: now}
+	t.order = append(t.order, key)
+}
+
+// remove 删除暂存的礼物连击
+func (t *Transform) remove(key string) {
+	delete(t.gifts, key)
+	for i, existing := range t.order {
+		if existing == key {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// addValue 累加归一化后的总价值，币种不一致（或只有一方归一化）时不再保留
+func (c *giftCombo) addValue(value *models.Money) {
+	if c.data.Value == nil {
+		return
+	}
+	if value == nil || value.Currency != c.data.Value.Currency {
+		c.data.Value = nil
+		return
+	}
+	c.data.Value.Amount += value.Amount
+}
+
+// message 生成聚合后的礼物消息，单价为平均单价，数量乘单价等于累计总价
+func (c *giftCombo) message() *models.Message {
+	data := c.data
+	if data.Num > 0 {
+		data.Price = c.total / float64(data.Num)
+	}
+	return aggregated(c.msg, &data)
+}
+
+// message 生成聚合后的点赞消息
+func (b *likeBatch) message() *models.Message {
+	data := b.data
+	return aggregated(b.msg, &data)
+}
+
+// aggregated 基于第一条消息生成聚合消息
+//
+// RawData 由聚合结果重新生成，死信、外部进程和格式化后的 SourceData 看到的
+// 都是累加后的数量和总价，而不是第一条消息的数据。
+func aggregated(first *models.Message, data models.MessageData) *models.Message {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		raw = nil // 序列化信封时由 Data 生成
+	}
+	return &models.Message{
+		RID:      first.RID,
+		Platform: first.Platform,
+		Type:     first.Type,
+		Data:     data,
+		RawData:  raw,
+		Meta:     first.Meta,
+	}
+}
+
+// userKey 暂存项的键：房间 + 用户 ID，原始数据中没有 ID 时退回用户名
+func userKey(msg *models.Message, name string) string {
+	user := "name:" + name
+	if sender := msg.Sender(); sender != nil && sender.ID != "" {
+		user = "id:" + sender.ID
+	}
+	return string(msg.Platform) + "/" + msg.RID + "/" + user
+}
+
+func init() {
+	plugin.Register("aggregate_transform", New, plugin.PluginInfo{
+		Name: "aggregate_transform",
+		Type: plugin.TypeTransform,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:    "gift_window",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultGiftWindow.Seconds(),
+				Desc:    "礼物连击结束判定（秒）：超过该时间没有新的同种礼物即输出，0 表示不聚合礼物",
+			},
+			{
+				Name:    "like_window",
+				Type:    plugin.FieldTypeNumber,
+				Default: defaultLikeWindow.Seconds(),
+				Desc:    "点赞合并窗口（秒），每个用户每个窗口输出一条，0 表示不聚合点赞",
+			},
+		},
+	})
+}
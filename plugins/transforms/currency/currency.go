@@ -0,0 +1,144 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// Transform 币种归一化转换器
+//
+// 按平台换算表把礼物、订阅、开通会员和 SuperChat 的原始价格换算为同一币种的总价值，
+// 写入数据的 Value 字段，之后的金额显示、表达式阈值和统计可以跨平台比较。
+// 需要放在 format_transform 之前，其余消息原样通过。
+type Transform struct {
+	*plugin.BasePlugin
+
+	currency       string
+	rates          rates // 礼物、订阅和会员价格的换算表
+	superChatRates rates // SuperChat 金额的换算表
+}
+
+// New 创建币种归一化转换器
+func New() plugin.Plugin {
+	return &Transform{
+		BasePlugin: plugin.NewBasePlugin("currency_transform", plugin.TypeTransform),
+	}
+}
+
+// Init 初始化插件，币种或换算表无效时返回错误
+func (t *Transform) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := t.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	currency, _ := config["currency"].(string)
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return err
+	}
+	t.currency = currency
+
+	if t.rates, err = parseRates(config["rates"], defaultRates); err != nil {
+		return fmt.Errorf("rates: %w", err)
+	}
+	if t.superChatRates, err = parseRates(config["superchat_rates"], defaultSuperChatRates); err != nil {
+		return fmt.Errorf("superchat_rates: %w", err)
+	}
+
+	return nil
+}
+
+// Transform 为带价格的消息填充归一化后的总价值
+func (t *Transform) Transform(ctx context.Context, msg *models.Message) (*models.Message, error) {
+	platform := string(msg.Platform)
+
+	var data models.MessageData
+	switch d := msg.Data.(type) {
+	case *models.GiftData:
+		normalized := *d
+		normalized.Value = t.money(d.Price*float64(d.Num), t.rates.rate(platform))
+		data = &normalized
+	case *models.SubscribeData:
+		normalized := *d
+		normalized.Value = t.money(d.Price*float64(d.Num), t.rates.rate(platform))
+		data = &normalized
+	case *models.GuardData:
+		normalized := *d
+		normalized.Value = t.money(d.Price*float64(d.Num), t.rates.rate(platform))
+		data = &normalized
+	case *models.SuperChatData:
+		normalized := *d
+		normalized.Value = t.money(d.Price, t.superChatRates.rate(platform))
+		data = &normalized
+	default:
+		return msg, nil
+	}
+
+	// RawData 保持为平台原始数据，归一化的价值只写入 Data
+	return &models.Message{
+		RID:      msg.RID,
+		Platform: msg.Platform,
+		Type:     msg.Type,
+		Data:     data,
+		RawData:  msg.RawData,
+		Meta:     msg.Meta,
+	}, nil
+}
+
+// money 按系数换算金额
+func (t *Transform) money(amount, factor float64) *models.Money {
+	return &models.Money{Amount: amount * factor, Currency: t.currency}
+}
+
+// parseCurrency 解析 ISO 4217 币种代码，空字符串返回 CNY
+func parseCurrency(s string) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return models.CurrencyCNY, nil
+	}
+	if len(s) != 3 || strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("invalid currency %q (want ISO 4217 code such as CNY)", s)
+	}
+	return s, nil
+}
+
+// validateCurrency 配置保存前检查币种代码
+func validateCurrency(value interface{}) error {
+	s, _ := value.(string)
+	_, err := parseCurrency(s)
+	return err
+}
+
+func init() {
+	plugin.Register("currency_transform", New, plugin.PluginInfo{
+		Name: "currency_transform",
+		Type: plugin.TypeTransform,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:     "currency",
+				Type:     plugin.FieldTypeString,
+				Default:  models.CurrencyCNY,
+				Desc:     "目标币种（ISO 4217 代码，如 CNY、USD）",
+				Validate: validateCurrency,
+			},
+			{
+				Name:     "rates",
+				Type:     plugin.FieldTypeString,
+				Default:  defaultRates.String(),
+				Desc:     "礼物、订阅和会员价格换算表，平台=系数，逗号分隔（价格 × 系数 = 目标币种金额，未列出的平台按 1）",
+				Validate: validateRates,
+			},
+			{
+				Name:     "superchat_rates",
+				Type:     plugin.FieldTypeString,
+				Default:  defaultSuperChatRates.String(),
+				Desc:     "SuperChat 金额换算表，格式同 rates（默认各平台均按 1）",
+				Validate: validateRates,
+			},
+		},
+	})
+}
@@ -0,0 +1,114 @@
+package currency
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+)
+
+// rates 平台 → 换算系数（平台价格 × 系数 = 目标币种金额）
+type rates map[string]float64
+
+// 默认换算表：礼物和订阅价格按各平台虚拟货币计价，SuperChat 金额已是人民币
+var (
+	defaultRates = rates{
+		"bilibili": 0.1,   // 电池，10 电池 = 1 元
+		"douyin":   0.1,   // 抖币，10 抖币 = 1 元
+		"kuaishou": 0.1,   // 快币，10 快币 = 1 元
+		"douyu":    0.1,   // 鱼翅，1 鱼翅 = 0.1 元
+		"huya":     0.001, // 金豆，1000 金豆 = 1 元
+	}
+	defaultSuperChatRates = rates{}
+)
+
+// rate 返回平台的换算系数，换算表中没有的平台按 1 计算
+func (r rates) rate(platform string) float64 {
+	if factor, ok := r[platform]; ok {
+		return factor
+	}
+	return 1
+}
+
+// String 返回 "平台=系数" 逗号分隔的形式，用于配置默认值
+func (r rates) String() string {
+	platforms := make([]string, 0, len(r))
+	for platform := range r {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	items := make([]string, len(platforms))
+	for i, platform := range platforms {
+		items[i] = platform + "=" + strconv.FormatFloat(r[platform], 'f', -1, 64)
+	}
+	return strings.Join(items, ",")
+}
+
+// parseRates 读取换算表配置：YAML 映射，或 "平台=系数" 以逗号、换行分隔的字符串；
+// 未配置时返回默认值，配置的平台覆盖默认值
+func parseRates(value interface{}, def rates) (rates, error) {
+	result := make(rates, len(def))
+	for platform, factor := range def {
+		result[platform] = factor
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for platform, raw := range v {
+			factor, ok := plugin.ParseNumber(raw)
+			if !ok {
+				return nil, fmt.Errorf("invalid rate for %s: %v", platform, raw)
+			}
+			if err := result.set(platform, factor); err != nil {
+				return nil, err
+			}
+		}
+	case string:
+		items := strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == '\n'
+		})
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			platform, raw, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid rate %q (want platform=factor)", item)
+			}
+			factor, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate for %s: %q", strings.TrimSpace(platform), raw)
+			}
+			if err := result.set(platform, factor); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid rates %v (want platform=factor list)", value)
+	}
+
+	return result, nil
+}
+
+// set 设置平台的换算系数
+func (r rates) set(platform string, factor float64) error {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform == "" {
+		return fmt.Errorf("empty platform in rates")
+	}
+	if factor < 0 {
+		return fmt.Errorf("invalid rate %v for %s (want >= 0)", factor, platform)
+	}
+	r[platform] = factor
+	return nil
+}
+
+// validateRates 配置保存前检查换算表
+func validateRates(value interface{}) error {
+	_, err := parseRates(value, nil)
+	return err
+}
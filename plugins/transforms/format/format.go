@@ -2,9 +2,10 @@ package format
 
 import (
 	"context"
-	"fmt"
-	"time"
+	"strings"
+	"text/template"
 
+	"github.com/xifan2333/dmnotifier/internal/i18n"
 	"github.com/xifan2333/dmnotifier/internal/plugin"
 	"github.com/xifan2333/dmnotifier/pkg/models"
 )
@@ -22,8 +23,13 @@ var platformIcons = map[string]string{
 const defaultUserAvatar = "https://cdn.jsdelivr.net/npm/remixicon@3.5.0/icons/User/user-fill.svg"
 
 // Transform 格式化转换器
+//
+// 各消息类型的内容文本由 text/template 模板生成，默认模板来自 locale 对应的消息目录，
+// 可通过 <type>_template 配置覆盖。
 type Transform struct {
 	*plugin.BasePlugin
+	locale    i18n.Locale
+	templates map[models.MessageType]*template.Template
 }
 
 // New 创建格式化转换器
@@ -33,13 +39,50 @@ func New() plugin.Plugin {
 	}
 }
 
+// Init 初始化插件，语言环境不支持或模板无法解析时返回错误
+func (t *Transform) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := t.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	localeName, _ := config["locale"].(string)
+	locale, err := i18n.Parse(localeName)
+	if err != nil {
+		return err
+	}
+	t.locale = locale
+
+	t.templates = make(map[models.MessageType]*template.Template, len(templateTypes))
+	for _, msgType := range templateTypes {
+		source, _ := config[templateField(msgType)].(string)
+		if source == "" {
+			source = defaultTemplate(locale, msgType)
+		}
+
+		tmpl, err := parseTemplate(locale, msgType, source)
+		if err != nil {
+			return err
+		}
+		t.templates[msgType] = tmpl
+	}
+
+	return nil
+}
+
 // Transform 转换消息为统一格式
 func (t *Transform) Transform(ctx context.Context, msg *models.Message) (*models.Message, error) {
-	formatted := t.convertToFormatted(msg)
+	formatted, err := t.convertToFormatted(msg)
+	if err != nil {
+		return nil, err
+	}
 	if formatted == nil {
 		// 不支持的消息类型，返回原消息
 		return msg, nil
 	}
+	if value, ok := models.ValueOf(msg.Data); ok {
+		formatted.Value = &value
+	}
+	formatted.User = msg.Sender()
 
 	// 创建新消息，使用统一格式
 	newMsg := &models.Message{
@@ -48,16 +91,36 @@ func (t *Transform) Transform(ctx context.Context, msg *models.Message) (*models
 		RID:      msg.RID,
 		Data:     formatted,
 		RawData:  msg.RawData,
+		Meta:     msg.Meta,
 	}
 
 	return newMsg, nil
 }
 
-// convertToFormatted 将消息转换为统一格式
-func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMessage {
-	timestamp := time.Now()
+// convertToFormatted 将消息转换为统一格式，不支持的消息类型返回 nil
+func (t *Transform) convertToFormatted(msg *models.Message) (*models.FormattedMessage, error) {
+	timestamp := msg.Time()
 	platform := string(msg.Platform)
 
+	// 已格式化或未解析的消息原样通过
+	if _, formatted := msg.Data.(*models.FormattedMessage); formatted || msg.Data == nil {
+		return nil, nil
+	}
+
+	// 未知类型共用 unknown_template
+	templateType := msg.Type
+	if _, unknown := msg.Data.(*models.UnknownData); unknown {
+		templateType = models.TypeUnknown
+	}
+	tmpl, ok := t.templates[templateType]
+	if !ok {
+		return nil, nil
+	}
+	content, err := render(tmpl, msg, msg.Data)
+	if err != nil {
+		return nil, err
+	}
+
 	switch data := msg.Data.(type) {
 	case *models.ChatData:
 
@@ -65,15 +128,15 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			UserName:    data.Name,
 			Platform:    platform,
 			Avatar:      t.getAvatar(data.Avatar, platform),
-			Content:     data.Content,
+			Content:     content,
+			Segments:    segments(content, data.Emoticon),
 			Timestamp:   timestamp,
 			Type:        "chat",
 			MessageType: models.TypeChat,
-		}
+		}, nil
 
 	case *models.SuperChatData:
 
-		content := fmt.Sprintf("%.2f 元: %s", data.Price, data.Content)
 		return &models.FormattedMessage{
 			UserName:    data.Name,
 			Platform:    platform,
@@ -82,12 +145,10 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "superchat",
 			MessageType: models.TypeSuperChat,
-		}
+		}, nil
 
 	case *models.GiftData:
 
-		totalPrice := data.Price * float64(data.Num)
-		content := fmt.Sprintf("送出了 %d 个 %s (%.2f 元)", data.Num, data.Item, totalPrice)
 		return &models.FormattedMessage{
 			UserName:    data.Name,
 			Platform:    platform,
@@ -96,11 +157,10 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "gift",
 			MessageType: models.TypeGift,
-		}
+		}, nil
 
 	case *models.SubscribeData:
 
-		content := fmt.Sprintf("订阅了 %s", data.Item)
 		return &models.FormattedMessage{
 			UserName:    data.Name,
 			Platform:    platform,
@@ -109,11 +169,10 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "subscribe",
 			MessageType: models.TypeSubscribe,
-		}
+		}, nil
 
 	case *models.LikeData:
 
-		content := fmt.Sprintf("点赞了 %d 次", data.Count)
 		return &models.FormattedMessage{
 			UserName:    data.Name,
 			Platform:    platform,
@@ -122,11 +181,10 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "like",
 			MessageType: models.TypeLike,
-		}
+		}, nil
 
 	case *models.EnterRoomData:
 
-		content := "进入了直播间"
 		return &models.FormattedMessage{
 			UserName:    data.Name,
 			Platform:    platform,
@@ -135,11 +193,10 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "enterroom",
 			MessageType: models.TypeEnterRoom,
-		}
+		}, nil
 
 	case *models.EndLiveData:
 
-		content := "直播结束"
 		return &models.FormattedMessage{
 			UserName:    "",
 			Platform:    platform,
@@ -148,10 +205,71 @@ func (t *Transform) convertToFormatted(msg *models.Message) *models.FormattedMes
 			Timestamp:   timestamp,
 			Type:        "endlive",
 			MessageType: models.TypeEndLive,
-		}
+		}, nil
+
+	case *models.GuardData:
+
+		return &models.FormattedMessage{
+			UserName:    data.Name,
+			Platform:    platform,
+			Avatar:      t.getAvatar(data.Avatar, platform),
+			Content:     content,
+			Timestamp:   timestamp,
+			Type:        "guard",
+			MessageType: models.TypeGuard,
+		}, nil
+
+	case *models.ShareData:
+
+		return &models.FormattedMessage{
+			UserName:    data.Name,
+			Platform:    platform,
+			Avatar:      t.getAvatar(data.Avatar, platform),
+			Content:     content,
+			Timestamp:   timestamp,
+			Type:        "share",
+			MessageType: models.TypeShare,
+		}, nil
+
+	case *models.FollowData:
+
+		return &models.FormattedMessage{
+			UserName:    data.Name,
+			Platform:    platform,
+			Avatar:      t.getAvatar(data.Avatar, platform),
+			Content:     content,
+			Timestamp:   timestamp,
+			Type:        "follow",
+			MessageType: models.TypeFollow,
+		}, nil
+
+	case *models.RoomStatsData:
+
+		return &models.FormattedMessage{
+			UserName:    "",
+			Platform:    platform,
+			Avatar:      t.getAvatar("", platform),
+			Content:     content,
+			Timestamp:   timestamp,
+			Type:        "roomstats",
+			MessageType: models.TypeRoomStats,
+		}, nil
+
+	case *models.UnknownData:
+
+		// Type 为原始类型名称的小写形式
+		return &models.FormattedMessage{
+			UserName:    data.Name,
+			Platform:    platform,
+			Avatar:      t.getAvatar(data.Avatar, platform),
+			Content:     content,
+			Timestamp:   timestamp,
+			Type:        strings.ToLower(string(data.Type)),
+			MessageType: data.Type,
+		}, nil
 
 	default:
-		return nil
+		return nil, nil
 	}
 }
 
@@ -170,10 +288,33 @@ func (t *Transform) getAvatar(avatar string, platform string) string {
 	return defaultUserAvatar
 }
 
+// configFields 返回语言环境和各消息类型模板的配置字段
+func configFields() []plugin.ConfigField {
+	fields := make([]plugin.ConfigField, 0, len(templateTypes)+1)
+	fields = append(fields, plugin.ConfigField{
+		Name:     "locale",
+		Type:     plugin.FieldTypeEnum,
+		Default:  string(i18n.Default),
+		Desc:     "语言环境，决定默认模板和数字、金额格式",
+		Options:  i18n.Names(),
+		Validate: i18n.Validate,
+	})
+	for _, msgType := range templateTypes {
+		fields = append(fields, plugin.ConfigField{
+			Name:     templateField(msgType),
+			Type:     plugin.FieldTypeString,
+			Default:  "",
+			Desc:     string(msgType) + " 内容模板（text/template），留空使用语言环境的默认模板",
+			Validate: templateValidator(msgType),
+		})
+	}
+	return fields
+}
+
 func init() {
 	plugin.Register("format_transform", New, plugin.PluginInfo{
 		Name:           "format_transform",
 		Type:           plugin.TypeTransform,
-		ConfigTemplate: []plugin.ConfigField{},
+		ConfigTemplate: configFields(),
 	})
 }
@@ -0,0 +1,71 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// 内容中的表情名（如 [doge]）和提及（如 @主播）
+var (
+	emoticonPattern = regexp.MustCompile(`\[[^\[\]\s]{1,16}\]`)
+	mentionPattern  = regexp.MustCompile(`@[^\s@:：,，]+`)
+)
+
+// segments 把内容切分为文本、表情和提及分段
+//
+// 平台只给出表情 URL 列表，没有位置信息：内容中的表情名数量与 URL 数量一致时
+// 按顺序一一替换，否则表情名保留为文本，表情依次追加在末尾。
+func segments(content string, emoticons []string) []models.Segment {
+	var result []models.Segment
+
+	names := emoticonPattern.FindAllStringIndex(content, -1)
+	if len(emoticons) > 0 && len(names) == len(emoticons) {
+		last := 0
+		for i, loc := range names {
+			result = append(result, textSegments(content[last:loc[0]])...)
+			result = append(result, models.Segment{
+				Type: models.SegmentEmoticon,
+				Text: content[loc[0]:loc[1]],
+				URL:  emoticons[i],
+			})
+			last = loc[1]
+		}
+		return append(result, textSegments(content[last:])...)
+	}
+
+	result = textSegments(content)
+	for _, url := range emoticons {
+		if url = strings.TrimSpace(url); url != "" {
+			result = append(result, models.Segment{Type: models.SegmentEmoticon, URL: url})
+		}
+	}
+	return result
+}
+
+// textSegments 把文本切分为普通文本和提及分段
+func textSegments(text string) []models.Segment {
+	var result []models.Segment
+
+	last := 0
+	for _, loc := range mentionPattern.FindAllStringIndex(text, -1) {
+		if loc[0] > 0 && isWordByte(text[loc[0]-1]) {
+			continue // 邮箱等单词中间的 @
+		}
+		if loc[0] > last {
+			result = append(result, models.Segment{Type: models.SegmentText, Text: text[last:loc[0]]})
+		}
+		result = append(result, models.Segment{Type: models.SegmentMention, Text: text[loc[0]+1 : loc[1]]})
+		last = loc[1]
+	}
+	if last < len(text) {
+		result = append(result, models.Segment{Type: models.SegmentText, Text: text[last:]})
+	}
+	return result
+}
+
+// isWordByte 判断是否为 ASCII 字母或数字
+func isWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,115 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/xifan2333/dmnotifier/internal/i18n"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// templateTypes 模板配置字段的顺序
+var templateTypes = []models.MessageType{
+	models.TypeChat,
+	models.TypeSuperChat,
+	models.TypeGift,
+	models.TypeSubscribe,
+	models.TypeLike,
+	models.TypeEnterRoom,
+	models.TypeEndLive,
+	models.TypeGuard,
+	models.TypeShare,
+	models.TypeFollow,
+	models.TypeRoomStats,
+	models.TypeUnknown,
+}
+
+// sampleData 各消息类型的零值数据，用于保存前试运行模板
+var sampleData = map[models.MessageType]models.MessageData{
+	models.TypeChat:      &models.ChatData{},
+	models.TypeSuperChat: &models.SuperChatData{},
+	models.TypeGift:      &models.GiftData{},
+	models.TypeSubscribe: &models.SubscribeData{},
+	models.TypeLike:      &models.LikeData{},
+	models.TypeEnterRoom: &models.EnterRoomData{},
+	models.TypeEndLive:   &models.EndLiveData{},
+	models.TypeGuard:     &models.GuardData{},
+	models.TypeShare:     &models.ShareData{},
+	models.TypeFollow:    &models.FollowData{},
+	models.TypeRoomStats: &models.RoomStatsData{},
+	models.TypeUnknown:   &models.UnknownData{},
+}
+
+// templateField 返回消息类型对应的配置字段名（如 gift_template）
+func templateField(msgType models.MessageType) string {
+	return strings.ToLower(string(msgType)) + "_template"
+}
+
+// defaultTemplate 返回语言环境中消息类型的默认模板
+func defaultTemplate(locale i18n.Locale, msgType models.MessageType) string {
+	return locale.Text("format." + strings.ToLower(string(msgType)))
+}
+
+// parseTemplate 解析模板，可使用语言环境的 number、money、plural 函数，引用不存在的字段时执行报错
+func parseTemplate(locale i18n.Locale, msgType models.MessageType, source string) (*template.Template, error) {
+	tmpl, err := template.New(templateField(msgType)).Funcs(locale.Funcs()).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", templateField(msgType), err)
+	}
+	return tmpl, nil
+}
+
+// templateData 模板可访问的字段：类型化数据的全部字段，加上 Platform、RID、Type，
+// 带价格的消息另有 Total（models.Money 总价值）
+func templateData(msg *models.Message, data models.MessageData) map[string]interface{} {
+	fields := map[string]interface{}{
+		"Platform": string(msg.Platform),
+		"RID":      msg.RID,
+		"Type":     string(msg.Type),
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				fields[field.Name] = v.Field(i).Interface()
+			}
+		}
+	}
+
+	if total, ok := models.ValueOf(data); ok {
+		fields["Total"] = total
+	}
+
+	return fields
+}
+
+// render 按模板生成内容文本
+func render(tmpl *template.Template, msg *models.Message, data models.MessageData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, templateData(msg, data)); err != nil {
+		return "", fmt.Errorf("render %s: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// templateValidator 返回配置保存前的模板校验函数：解析并以零值数据试运行
+func templateValidator(msgType models.MessageType) func(value interface{}) error {
+	return func(value interface{}) error {
+		source, _ := value.(string)
+		if strings.TrimSpace(source) == "" {
+			return nil
+		}
+
+		tmpl, err := parseTemplate(i18n.Default, msgType, source)
+		if err != nil {
+			return err
+		}
+
+		sample := &models.Message{Type: msgType}
+		_, err = render(tmpl, sample, sampleData[msgType])
+		return err
+	}
+}
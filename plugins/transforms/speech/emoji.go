@@ -0,0 +1,97 @@
+package speech
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 表情处理方式
+const (
+	emojiStrip = "strip" // 删除
+	emojiName  = "name"  // 读出名称
+	emojiKeep  = "keep"  // 保留原文
+)
+
+// unknownEmojiName 名称表中没有的 emoji 的读法
+const unknownEmojiName = "表情"
+
+// emojiNames 常见 emoji 的读法
+var emojiNames = map[rune]string{
+	'😀': "笑脸", '😁': "笑脸", '😄': "笑脸", '😊': "微笑", '🙂': "微笑",
+	'😂': "笑哭", '🤣': "笑哭", '😅': "尴尬", '😆': "大笑", '😍': "喜欢",
+	'🥰': "喜欢", '😘': "飞吻", '😭': "大哭", '😢': "哭", '😡': "生气",
+	'😠': "生气", '😱': "惊恐", '😳': "脸红", '🤔': "思考", '😏': "坏笑",
+	'🙄': "白眼", '😴': "睡觉", '🥺': "可怜", '😎': "酷", '🤡': "小丑",
+	'👍': "点赞", '👎': "踩", '👏': "鼓掌", '🙏': "拜托", '💪': "加油",
+	'👌': "好的", '✌': "耶", '🤝': "握手", '👋': "挥手",
+	'❤': "爱心", '💕': "爱心", '💖': "爱心", '💔': "心碎",
+	'🔥': "火", '🎉': "庆祝", '🌹': "玫瑰", '🍺': "干杯", '🎂': "蛋糕",
+	'⭐': "星星", '✨': "闪光", '💯': "满分", '🐶': "狗头", '🐱': "猫",
+}
+
+// bracketPattern 平台文字表情，如 B 站的 [doge]
+var bracketPattern = regexp.MustCompile(`\[([^\[\]\s]{1,16})\]`)
+
+// speakBrackets 处理文字表情：strip 删除，name 只读出括号中的名称
+func speakBrackets(text, mode string) string {
+	switch mode {
+	case emojiStrip:
+		return bracketPattern.ReplaceAllString(text, "")
+	case emojiName:
+		return bracketPattern.ReplaceAllString(text, "$1")
+	}
+	return text
+}
+
+// speakEmoji 处理 emoji：strip 删除，name 读出名称，连续相同的名称只读一次
+func speakEmoji(text, mode string) string {
+	if mode == emojiKeep {
+		return text
+	}
+
+	var b strings.Builder
+	last := "" // 上一个读出的名称，被文本隔开后清空
+	for _, r := range text {
+		if isEmojiModifier(r) {
+			continue
+		}
+		if !isEmoji(r) {
+			b.WriteRune(r)
+			last = ""
+			continue
+		}
+		if mode != emojiName {
+			continue
+		}
+
+		name, ok := emojiNames[r]
+		if !ok {
+			name = unknownEmojiName
+		}
+		if name != last {
+			b.WriteString(name)
+			last = name
+		}
+	}
+	return b.String()
+}
+
+// isEmoji 判断是否为 emoji 主体字符
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F300 && r <= 0x1FAFF: // 符号与象形文字、表情、交通、补充符号
+		return true
+	case r >= 0x1F1E6 && r <= 0x1F1FF: // 区域指示符（国旗）
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号、装饰符号
+		return true
+	case r == 0x2B50 || r == 0x2B55: // ⭐ ⭕
+		return true
+	}
+	return false
+}
+
+// isEmojiModifier 判断是否为 emoji 的组合字符（变体选择符、零宽连接符、肤色、按键帽）
+func isEmojiModifier(r rune) bool {
+	return r == 0xFE0F || r == 0x200D || r == 0x20E3 || r >= 0x1F3FB && r <= 0x1F3FF
+}
@@ -0,0 +1,123 @@
+package speech
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 数字读法
+var (
+	digitNames = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	unitNames  = []string{"千", "百", "十", ""}
+)
+
+// maxValueDigits 整数部分超过该位数（如 QQ 号、手机号）时逐位读出
+const maxValueDigits = 8
+
+// 金额、百分数和普通数字，数字可带千位分组（如 4,500.00）
+var (
+	numberSource   = `\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`
+	moneyPattern   = regexp.MustCompile(`(CN¥|¥|￥|US\$|\$)\s?(` + numberSource + `)`)
+	percentPattern = regexp.MustCompile(`(` + numberSource + `)\s?[%％]`)
+	numberPattern  = regexp.MustCompile(numberSource)
+)
+
+// currencyWords 金额前缀的读法
+var currencyWords = map[string]string{
+	"CN¥": "元",
+	"¥":   "元",
+	"￥":   "元",
+	"US$": "美元",
+	"$":   "美元",
+}
+
+// speakNumbers 把文本中的金额、百分数和数字转换为中文读法
+func speakNumbers(text string) string {
+	text = moneyPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := moneyPattern.FindStringSubmatch(match)
+		return spokenNumber(groups[2]) + currencyWords[groups[1]]
+	})
+	text = percentPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return "百分之" + spokenNumber(percentPattern.FindStringSubmatch(match)[1])
+	})
+	return numberPattern.ReplaceAllStringFunc(text, spokenNumber)
+}
+
+// spokenNumber 返回数字的中文读法，小数部分全为 0 时省略（如 30.00 读作三十）
+func spokenNumber(number string) string {
+	number = strings.ReplaceAll(number, ",", "")
+	integer, fraction, _ := strings.Cut(number, ".")
+
+	var b strings.Builder
+	if len(integer) > maxValueDigits || len(integer) > 1 && integer[0] == '0' {
+		b.WriteString(spokenDigits(integer))
+	} else {
+		b.WriteString(spokenInteger(integer))
+	}
+	if strings.Trim(fraction, "0") != "" {
+		b.WriteString("点")
+		b.WriteString(spokenDigits(strings.TrimRight(fraction, "0")))
+	}
+	return b.String()
+}
+
+// spokenDigits 逐位读出数字
+func spokenDigits(digits string) string {
+	var b strings.Builder
+	for _, d := range digits {
+		b.WriteString(digitNames[d-'0'])
+	}
+	return b.String()
+}
+
+// spokenInteger 按数值读出不超过 maxValueDigits 位的整数（如 10086 读作一万零八十六）
+func spokenInteger(digits string) string {
+	n := 0
+	for _, d := range digits {
+		n = n*10 + int(d-'0')
+	}
+	if n == 0 {
+		return digitNames[0]
+	}
+
+	var b strings.Builder
+	high, low := n/10000, n%10000
+	if high > 0 {
+		b.WriteString(section(high))
+		b.WriteString("万")
+		if low > 0 && low < 1000 {
+			b.WriteString(digitNames[0])
+		}
+	}
+	if low > 0 {
+		b.WriteString(section(low))
+	}
+
+	// 十几、十几万不读"一十"
+	spoken := b.String()
+	if strings.HasPrefix(spoken, "一十") {
+		spoken = strings.TrimPrefix(spoken, "一")
+	}
+	return spoken
+}
+
+// section 读出 1 到 9999 之间的整数，中间的连续 0 读作一个"零"
+func section(n int) string {
+	var b strings.Builder
+	started, zero := false, false
+	for i, divisor := range []int{1000, 100, 10, 1} {
+		d := n / divisor % 10
+		if d == 0 {
+			zero = started
+			continue
+		}
+		if zero {
+			b.WriteString(digitNames[0])
+			zero = false
+		}
+		b.WriteString(digitNames[d])
+		b.WriteString(unitNames[i])
+		started = true
+	}
+	return b.String()
+}
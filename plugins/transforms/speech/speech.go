@@ -0,0 +1,251 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/xifan2333/dmnotifier/internal/plugin"
+	"github.com/xifan2333/dmnotifier/pkg/models"
+)
+
+// 默认配置
+const (
+	defaultMaxRepeat      = 2
+	defaultURLWord        = "链接"
+	defaultMaxLength      = 60
+	defaultTruncateSuffix = "等等"
+)
+
+// urlPattern 网址
+var urlPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s\p{Han}，。！？]+`)
+
+// laughPattern 表示笑的 2333…
+var laughPattern = regexp.MustCompile(`23{3,}`)
+
+// Transform 语音播报文本规整转换器
+//
+// 把格式化消息的内容整理为适合朗读的文本：网址替换为短词、删除或读出表情、
+// 折叠连续重复的字符、把数字和金额转换为中文读法、截断过长的内容。
+// 用户名只做表情和重复字符的处理。放在 TTS 自己的链上，在 format_transform 之后执行。
+type Transform struct {
+	*plugin.BasePlugin
+
+	maxRepeat      int    // 为 0 时不折叠
+	emoji          string // strip、name 或 keep
+	urlWord        string // 为空时删除网址
+	numbers        bool
+	maxLength      int // 内容的最大字符数，为 0 时不截断
+	truncateSuffix string
+}
+
+// New 创建语音文本规整转换器
+func New() plugin.Plugin {
+	return &Transform{
+		BasePlugin: plugin.NewBasePlugin("speech_transform", plugin.TypeTransform),
+	}
+}
+
+// Init 初始化插件
+func (t *Transform) Init(ctx context.Context, config map[string]interface{}) error {
+	if err := t.BasePlugin.Init(ctx, config); err != nil {
+		return err
+	}
+
+	t.maxRepeat = int(plugin.NumberValue(config["max_repeat"], defaultMaxRepeat))
+	t.maxLength = int(plugin.NumberValue(config["max_length"], defaultMaxLength))
+	if t.maxRepeat < 0 || t.maxLength < 0 {
+		return fmt.Errorf("max_repeat and max_length must be >= 0")
+	}
+
+	t.emoji = plugin.StringValue(config["emoji"], emojiStrip)
+	if err := validateEmoji(t.emoji); err != nil {
+		return err
+	}
+
+	t.urlWord = defaultURLWord
+	if word, ok := config["url_word"].(string); ok {
+		t.urlWord = strings.TrimSpace(word)
+	}
+	t.truncateSuffix = defaultTruncateSuffix
+	if suffix, ok := config["truncate_suffix"].(string); ok {
+		t.truncateSuffix = suffix
+	}
+
+	t.numbers = true
+	if numbers, ok := config["numbers"].(bool); ok {
+		t.numbers = numbers
+	}
+
+	return nil
+}
+
+// Transform 规整格式化消息的用户名和内容，其余消息原样通过
+func (t *Transform) Transform(ctx context.Context, msg *models.Message) (*models.Message, error) {
+	formatted, ok := msg.Data.(*models.FormattedMessage)
+	if !ok {
+		return msg, nil
+	}
+
+	spoken := *formatted
+	spoken.UserName = t.normalizeName(formatted.UserName)
+	spoken.Content = t.normalize(formatted.Content)
+	spoken.Segments = nil // 分段与规整后的内容不再对应
+
+	return &models.Message{
+		RID:      msg.RID,
+		Platform: msg.Platform,
+		Type:     msg.Type,
+		Data:     &spoken,
+		RawData:  msg.RawData,
+		Meta:     msg.Meta,
+	}, nil
+}
+
+// normalize 规整内容文本
+func (t *Transform) normalize(text string) string {
+	text = urlPattern.ReplaceAllString(text, t.urlWord)
+	text = speakBrackets(text, t.emoji)
+	text = speakEmoji(text, t.emoji)
+	text = collapseLaughs(text, t.maxRepeat)
+	text = collapseRepeats(text, t.maxRepeat)
+	if t.numbers {
+		text = speakNumbers(text)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	return truncate(text, t.maxLength, t.truncateSuffix)
+}
+
+// normalizeName 规整用户名
+func (t *Transform) normalizeName(name string) string {
+	name = speakEmoji(name, t.emoji)
+	name = collapseLaughs(name, t.maxRepeat)
+	name = collapseRepeats(name, t.maxRepeat)
+	return strings.TrimSpace(name)
+}
+
+// collapseRepeats 把连续超过 max 个的相同字符折叠为 max 个（如 哈哈哈哈 → 哈哈），
+// 数字和空白不折叠，避免改变 1999、333.00 这样的数值
+func collapseRepeats(text string, max int) string {
+	if max <= 0 {
+		return text
+	}
+
+	var b strings.Builder
+	var last rune
+	run := 0
+	for _, r := range text {
+		if r == last {
+			run++
+		} else {
+			last, run = r, 1
+		}
+		if run > max && !unicode.IsDigit(r) && !unicode.IsSpace(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// collapseLaughs 把独立出现的 2333… 折叠为 max 个 3（如 2333333 → 233），
+// 前后紧挨数字、小数点或千位分隔符的不算，避免改变 12333.00 这样的数值
+func collapseLaughs(text string, max int) string {
+	if max <= 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range laughPattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && strings.ContainsRune("0123456789.,", rune(text[start-1])) ||
+			end < len(text) && strings.ContainsRune("0123456789.,", rune(text[end])) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString("2" + strings.Repeat("3", min(max, end-start-1)))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// truncate 截断超过 max 个字符的文本并追加 suffix
+func truncate(text string, max int, suffix string) string {
+	if max <= 0 {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return strings.TrimRightFunc(string(runes[:max]), unicode.IsSpace) + suffix
+}
+
+// validateEmoji 检查表情处理方式
+func validateEmoji(mode string) error {
+	switch mode {
+	case emojiStrip, emojiName, emojiKeep:
+		return nil
+	}
+	return fmt.Errorf("invalid emoji mode %q (want %s, %s or %s)", mode, emojiStrip, emojiName, emojiKeep)
+}
+
+// validateCount 配置保存前检查非负整数
+func validateCount(value interface{}) error {
+	if n := plugin.NumberValue(value, 0); n < 0 {
+		return fmt.Errorf("must be >= 0, got %v", n)
+	}
+	return nil
+}
+
+func init() {
+	plugin.Register("speech_transform", New, plugin.PluginInfo{
+		Name: "speech_transform",
+		Type: plugin.TypeTransform,
+		ConfigTemplate: []plugin.ConfigField{
+			{
+				Name:     "max_repeat",
+				Type:     plugin.FieldTypeNumber,
+				Default:  defaultMaxRepeat,
+				Desc:     "连续相同字符最多保留的个数（如 2333333 读作 233），0 表示不折叠",
+				Validate: validateCount,
+			},
+			{
+				Name:    "emoji",
+				Type:    plugin.FieldTypeEnum,
+				Default: emojiStrip,
+				Desc:    "表情（emoji 和 [doge] 这样的文字表情）的处理：strip 删除，name 读出名称，keep 保留原文",
+				Options: []string{emojiStrip, emojiName, emojiKeep},
+			},
+			{
+				Name:    "url_word",
+				Type:    plugin.FieldTypeString,
+				Default: defaultURLWord,
+				Desc:    "网址替换为的词，留空表示删除网址",
+			},
+			{
+				Name:    "numbers",
+				Type:    plugin.FieldTypeBool,
+				Default: true,
+				Desc:    "把数字、金额和百分数转换为中文读法",
+			},
+			{
+				Name:     "max_length",
+				Type:     plugin.FieldTypeNumber,
+				Default:  defaultMaxLength,
+				Desc:     "内容的最大字数，超出部分截断，0 表示不截断",
+				Validate: validateCount,
+			},
+			{
+				Name:    "truncate_suffix",
+				Type:    plugin.FieldTypeString,
+				Default: defaultTruncateSuffix,
+				Desc:    "截断后追加的文字",
+			},
+		},
+	})
+}